@@ -0,0 +1,103 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: match isulad actions against policy action entries
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"regexp"
+	"strings"
+)
+
+// action entry prefixes selecting the match mode explicitly
+const (
+	exactPrefix = "exact:"
+	globPrefix  = "glob:"
+	regexPrefix = "regex:"
+)
+
+// matchAllEntry is the entry the empty entry of earlier policy files stands for
+const matchAllEntry = globPrefix + "*"
+
+type matchMode int
+
+const (
+	matchExact matchMode = iota
+	matchGlob
+	matchRegex
+	matchLegacyRegex
+)
+
+// parseActionEntry splits a policy action entry into its match mode and pattern.
+// Entries without prefix are exact names, globs if they contain '*' or '?',
+// and anchored regexes if they contain other regex metacharacters. In legacy
+// mode they are unanchored regexes, as in earlier policy files. The empty
+// entry matched every action as a regex and still does, it is deprecated.
+func parseActionEntry(entry string, legacy bool) (matchMode, string) {
+	switch {
+	case entry == "":
+		return matchGlob, "*"
+	case strings.HasPrefix(entry, exactPrefix):
+		return matchExact, strings.TrimPrefix(entry, exactPrefix)
+	case strings.HasPrefix(entry, globPrefix):
+		return matchGlob, strings.TrimPrefix(entry, globPrefix)
+	case strings.HasPrefix(entry, regexPrefix):
+		return matchRegex, strings.TrimPrefix(entry, regexPrefix)
+	case legacy:
+		return matchLegacyRegex, entry
+	case strings.ContainsAny(strings.NewReplacer("*", "", "?", "").Replace(entry), `\.+()|[]{}^$`):
+		return matchRegex, entry
+	case strings.ContainsAny(entry, "*?"):
+		return matchGlob, entry
+	}
+	return matchExact, entry
+}
+
+// globToRegex converts a glob pattern to an anchored regex
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// compileActionEntry compiles a policy action entry to a regex, nil is
+// returned for exact entries
+func compileActionEntry(entry string, legacy bool) (*regexp.Regexp, error) {
+	mode, pattern := parseActionEntry(entry, legacy)
+	switch mode {
+	case matchGlob:
+		return regexp.Compile(globToRegex(pattern))
+	case matchRegex:
+		return regexp.Compile("^(?:" + pattern + ")$")
+	case matchLegacyRegex:
+		return regexp.Compile(pattern)
+	}
+	return nil, nil
+}
+
+// isUnanchoredEntry reports whether a legacy entry matches any action
+// containing it rather than the whole action name
+func isUnanchoredEntry(entry string, legacy bool) bool {
+	mode, pattern := parseActionEntry(entry, legacy)
+	return mode == matchLegacyRegex && !(strings.HasPrefix(pattern, "^") && strings.HasSuffix(pattern, "$"))
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: action entry matching tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import "testing"
+
+func TestParseActionEntry(t *testing.T) {
+	cases := []struct {
+		entry   string
+		legacy  bool
+		mode    matchMode
+		pattern string
+	}{
+		{"container_start", false, matchExact, "container_start"},
+		{"exact:container_start", false, matchExact, "container_start"},
+		{"exact:container_*", false, matchExact, "container_*"},
+		{"glob:container_*", false, matchGlob, "container_*"},
+		{"regex:container_(start|stop)", false, matchRegex, "container_(start|stop)"},
+		{"container_*", false, matchGlob, "container_*"},
+		{"container_st?rt", false, matchGlob, "container_st?rt"},
+		{"container_(start|stop)", false, matchRegex, "container_(start|stop)"},
+		{"container_.*", false, matchRegex, "container_.*"},
+		{"^container_start$", false, matchRegex, "^container_start$"},
+		{"", false, matchGlob, "*"},
+		{"container_start", true, matchLegacyRegex, "container_start"},
+		{"container_*", true, matchLegacyRegex, "container_*"},
+		{"exact:container_start", true, matchExact, "container_start"},
+		{"glob:container_*", true, matchGlob, "container_*"},
+		{"regex:container_.*", true, matchRegex, "container_.*"},
+		{"", true, matchGlob, "*"},
+	}
+	for _, c := range cases {
+		mode, pattern := parseActionEntry(c.entry, c.legacy)
+		if mode != c.mode || pattern != c.pattern {
+			t.Errorf("parseActionEntry(%q, %t) = %d, %q, want %d, %q", c.entry, c.legacy, mode, pattern, c.mode, c.pattern)
+		}
+	}
+}
+
+func TestActionEntryMatch(t *testing.T) {
+	cases := []struct {
+		entry  string
+		legacy bool
+		action string
+		match  bool
+	}{
+		{"container_start", false, "container_start", true},
+		{"container_start", false, "container_start_exec", false},
+		{"exact:container_*", false, "container_start", false},
+		{"exact:container_*", false, "container_*", true},
+		{"glob:container_*", false, "container_start", true},
+		{"glob:container_*", false, "image_container_list", false},
+		{"glob:container_?top", false, "container_stop", true},
+		{"glob:container.*", false, "container_start", false},
+		{"regex:container_(start|stop)", false, "container_stop", true},
+		{"regex:container_(start|stop)", false, "container_stopped", false},
+		{"regex:start", false, "container_start", false},
+		{"container_*", false, "container_exec_start", true},
+		{"container_(start|stop)", false, "container_start", true},
+		{"container_(start|stop)", false, "x_container_start", false},
+		{"glob:*", false, "image_push", true},
+		{"", false, "image_push", true},
+		{"start", true, "container_start", true},
+		{"^container_start$", true, "container_start", true},
+		{"^container_start$", true, "container_start_exec", false},
+		{"glob:*", true, "image_push", true},
+		{"", true, "image_push", true},
+		{"exact:container_start", true, "container_start", true},
+		{"exact:start", true, "container_start", false},
+	}
+	for _, c := range cases {
+		re, err := compileActionEntry(c.entry, c.legacy)
+		if err != nil {
+			t.Errorf("compileActionEntry(%q, %t): %v", c.entry, c.legacy, err)
+			continue
+		}
+		_, name := parseActionEntry(c.entry, c.legacy)
+		match := name == c.action
+		if re != nil {
+			match = re.MatchString(c.action)
+		}
+		if match != c.match {
+			t.Errorf("entry %q (legacy %t) matches %q: %t, want %t", c.entry, c.legacy, c.action, match, c.match)
+		}
+	}
+}
+
+func TestActionEntryInvalid(t *testing.T) {
+	cases := []struct {
+		entry  string
+		legacy bool
+	}{
+		{"regex:container_(start", false},
+		{"container_(start", false},
+		{"*", true},
+		{"container_[", true},
+	}
+	for _, c := range cases {
+		if _, err := compileActionEntry(c.entry, c.legacy); err == nil {
+			t.Errorf("compileActionEntry(%q, %t) succeeded, want an error", c.entry, c.legacy)
+		}
+	}
+}
+
+func TestIsUnanchoredEntry(t *testing.T) {
+	cases := []struct {
+		entry      string
+		legacy     bool
+		unanchored bool
+	}{
+		{"container_start", true, true},
+		{"^container_start$", true, false},
+		{"^container_", true, true},
+		{"glob:container_*", true, false},
+		{"", true, false},
+		{"container_start", false, false},
+		{"container_.*", false, false},
+	}
+	for _, c := range cases {
+		if got := isUnanchoredEntry(c.entry, c.legacy); got != c.unanchored {
+			t.Errorf("isUnanchoredEntry(%q, %t) = %t, want %t", c.entry, c.legacy, got, c.unanchored)
+		}
+	}
+}
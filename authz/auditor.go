@@ -33,7 +33,8 @@ func NewAuditor() Auditor {
 	return &auditor{}
 }
 
-func (b *auditor) AuditRequest(req *authorization.Request, resp *authorization.Response) error {
+// AuditRequest audits a request with the action of its decision
+func (b *auditor) AuditRequest(req *authorization.Request, resp *authorization.Response, decision *Decision) error {
 
 	if req == nil || resp == nil {
 		return fmt.Errorf("Authorization request or response is nil")
@@ -44,9 +45,14 @@ func (b *auditor) AuditRequest(req *authorization.Request, resp *authorization.R
 		return err
 	}
 
+	action := UnknownAction
+	if decision != nil {
+		action = decision.Action
+	}
 	fields := logrus.Fields{
 		"method": req.RequestMethod,
 		"uri":    req.RequestURI,
+		"action": action,
 		"user":   req.User,
 		"allow":  resp.Allow,
 		"msg":    resp.Msg,
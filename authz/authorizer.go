@@ -14,15 +14,12 @@
 package authz
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/signal"
-	"path"
-	"regexp"
-	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/docker/docker/pkg/authorization"
 	"github.com/sirupsen/logrus"
@@ -30,146 +27,346 @@ import (
 
 // Policy is rbac policy
 type Policy struct {
-	Actions  []string `json:"actions"`  // Actions are the isulad actions
-	Users    []string `json:"users"`    // Users are the users for which this policy apply to
-	Name     string   `json:"name"`     // Name is the policy name
-	Readonly bool     `json:"readonly"` // Readonly indicates this policy only allow get commands
+	Actions       []string `json:"actions,omitempty"`       // Actions are the isulad actions
+	Classes       []string `json:"classes,omitempty"`       // Classes are the route classes whose actions this policy allows
+	Users         []string `json:"users"`                   // Users are the users for which this policy apply to
+	Name          string   `json:"name"`                    // Name is the policy name
+	Readonly      bool     `json:"readonly,omitempty"`      // Readonly indicates this policy only allow actions of class read
+	MinAPIVersion string   `json:"minApiVersion,omitempty"` // MinAPIVersion is the lowest api version the users may request
+	MaxAPIVersion string   `json:"maxApiVersion,omitempty"` // MaxAPIVersion is the highest api version the users may request
+
+	pos position // pos is where the policy was loaded from
 }
 
+// UnknownActionMode decides how requests to unknown routes are handled
+type UnknownActionMode string
+
+const (
+	// UnknownActionDeny denies all requests to unknown routes
+	UnknownActionDeny UnknownActionMode = "deny"
+	// UnknownActionAllow allows all requests to unknown routes without applying policies
+	UnknownActionAllow UnknownActionMode = "allow"
+	// UnknownActionAudit works as UnknownActionAllow and logs a warning for every such request
+	UnknownActionAudit UnknownActionMode = "audit"
+)
+
+// ParseUnknownActionMode converts a string to an UnknownActionMode
+func ParseUnknownActionMode(mode string) (UnknownActionMode, error) {
+	switch m := UnknownActionMode(mode); m {
+	case UnknownActionDeny, UnknownActionAllow, UnknownActionAudit:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid unknown action mode %q, must be one of deny, allow or audit", mode)
+}
+
+// rules for requests without api version prefix
+const (
+	// UnversionedAPIAllow skips api version checks for unversioned requests
+	UnversionedAPIAllow = "allow"
+	// UnversionedAPIDeny denies all unversioned requests
+	UnversionedAPIDeny = "deny"
+)
+
+// Config is the authorizer configuration
+type Config struct {
+	PolicyPath     string            // PolicyPath is the path of the policy file
+	PolicyDir      string            // PolicyDir is the optional drop-in directory loaded after the policy file
+	RoutesPath     string            // RoutesPath is the optional path of the routes file
+	UnknownAction  UnknownActionMode // UnknownAction decides how requests to unknown routes are handled
+	LegacyActions  bool              // LegacyActions matches unprefixed action entries as unanchored regexes
+	MinAPIVersion  string            // MinAPIVersion is the lowest api version allowed, empty for no limit
+	MaxAPIVersion  string            // MaxAPIVersion is the highest api version allowed, empty for no limit
+	UnversionedAPI string            // UnversionedAPI is allow, deny or the api version assumed for unversioned requests
+
+	ShadowPolicyPath string // ShadowPolicyPath is the optional candidate policy file evaluated without enforcing it
+	ShadowPolicyDir  string // ShadowPolicyDir is the optional candidate drop-in directory
+	ShadowAuditPath  string // ShadowAuditPath is the file the candidate disagreements are audited to, syslog if empty
+
+	LearningPath string // LearningPath enables learning mode, every request is allowed and recorded to this file
+}
+
+// Validate checks the policy paths and api version settings of the configuration
+func (c *Config) Validate() error {
+	if c.PolicyPath == "" && c.PolicyDir == "" && c.LearningPath == "" {
+		return fmt.Errorf("no policy file or directory configured")
+	}
+	if c.ShadowAuditPath != "" && c.ShadowPolicyPath == "" && c.ShadowPolicyDir == "" {
+		return fmt.Errorf("shadow audit log configured without shadow policy file or directory")
+	}
+	for _, v := range []string{c.MinAPIVersion, c.MaxAPIVersion} {
+		if v == "" {
+			continue
+		}
+		if err := ParseAPIVersion(v); err != nil {
+			return err
+		}
+	}
+	if c.MinAPIVersion != "" && c.MaxAPIVersion != "" && compareAPIVersions(c.MinAPIVersion, c.MaxAPIVersion) > 0 {
+		return fmt.Errorf("min api version %s is higher than max api version %s", c.MinAPIVersion, c.MaxAPIVersion)
+	}
+	switch c.UnversionedAPI {
+	case "", UnversionedAPIAllow, UnversionedAPIDeny:
+		return nil
+	}
+	if err := ParseAPIVersion(c.UnversionedAPI); err != nil {
+		return fmt.Errorf("invalid unversioned api rule %q, must be allow, deny or an api version", c.UnversionedAPI)
+	}
+	return nil
+}
+
+// policyCheckInterval is how often the policy files are checked for changes
+const policyCheckInterval = 2 * time.Second
+
 type authorizer struct {
-	policyPath string
-	policies   []Policy
+	config    Config
+	store     *policyStore
+	shadow    *shadow  // shadow evaluates the candidate policies, nil if none are configured
+	learner   *learner // learner records requests in learning mode, nil otherwise
+	candidate bool     // candidate is set for the authorizer of shadow policies
+
+	done      chan struct{} // done is closed to stop watching the policy files
+	closeOnce sync.Once
 }
 
 // NewAuthorizer creates a new authorizer
-func NewAuthorizer(policyPath string) Authorizer {
-	return &authorizer{policyPath: policyPath}
+func NewAuthorizer(config *Config) Authorizer {
+	return &authorizer{
+		config:  *config,
+		store:   newPolicyStore(&snapshot{engine: newEngine(config, newRouter(routes), nil)}),
+		shadow:  newShadow(config),
+		learner: newLearner(config),
+		done:    make(chan struct{}),
+	}
 }
 
 // Init loads the authz plugin configuration
 func (f *authorizer) Init() error {
+	// learning mode allows every request, it starts without valid policies
 	err := f.LoadPolicies()
-	if err != nil {
+	if err != nil && f.learner == nil {
 		return err
 	}
+	if f.shadow != nil {
+		if err := f.shadow.init(f.config.ShadowAuditPath); err != nil {
+			return fmt.Errorf("failed to open shadow audit log: %v", err)
+		}
+		go f.shadow.watchPolicies()
+	}
+	if f.learner != nil {
+		if err := f.learner.init(f.config.LearningPath); err != nil {
+			return fmt.Errorf("failed to open learning log: %v", err)
+		}
+		logrus.Warnf("Learning mode, every request is allowed and recorded to %s", f.config.LearningPath)
+	}
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGHUP)
 	go func() {
-		for range c {
-			if err := f.LoadPolicies(); err != nil {
-				logrus.Errorf("Error reloading policy %q", err.Error())
+		defer signal.Stop(c)
+		for {
+			select {
+			case <-c:
+				if err := f.LoadPolicies(); err != nil {
+					logrus.Errorf("Error reloading policy %q", err.Error())
+				}
+			case <-f.done:
+				return
 			}
 		}
 	}()
 
+	go f.watchPolicies()
+
 	return nil
 }
 
-func (f *authorizer) LoadPolicies() error {
-	policies, err := parsePolicy(f.policyPath)
-	if err != nil {
-		return err
+// watchPolicies reloads policies whenever the policy files change until the
+// authorizer is closed, it falls back to polling if inotify is not available
+// or keeps failing
+func (f *authorizer) watchPolicies() {
+	reload := func() {
+		if err := f.reload(false); err != nil {
+			logrus.Errorf("Error reloading policy %q", err.Error())
+		}
 	}
 
-	// Notify duplicate policies
-	policyMap := make(map[string]string)
-	for _, policy := range policies {
-		for _, u := range policy.Users {
-			if v, ok := policyMap[u]; ok {
-				logrus.Warnf(
-					"[policy: %s] User %q already appears in policy %q. Only single policy applies.",
-					policy.Name,
-					u,
-					v,
-				)
-			}
-			policyMap[u] = policy.Name
+	w, err := newPolicyWatcher(f.watchedPaths)
+	if err == nil {
+		err = w.run(reload, f.done)
+		if err == nil {
+			return
 		}
 	}
-	f.policies = policies
+	logrus.Warnf("Failed to watch policy files, polling every %s instead: %v", policyCheckInterval, err)
+	f.pollPolicies(policyCheckInterval, reload)
+}
+
+// pollPolicies calls reload every interval until the authorizer is closed
+func (f *authorizer) pollPolicies(interval time.Duration, reload func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reload()
+		case <-f.done:
+			return
+		}
+	}
+}
+
+// Close stops watching the policy files, of the shadow policies as well
+func (f *authorizer) Close() error {
+	f.closeOnce.Do(func() { close(f.done) })
+	if f.shadow != nil {
+		return f.shadow.Close()
+	}
 	return nil
 }
 
-func parsePolicy(policyPath string) ([]Policy, error) {
-	data, err := ioutil.ReadFile(path.Join(policyPath))
-	if err != nil {
-		return nil, err
+// watchedPaths returns the configured policy and routes paths and every file
+// the current snapshot was loaded from, a change to any of them reloads
+func (f *authorizer) watchedPaths() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, p := range append([]string{f.config.PolicyPath, f.config.PolicyDir, f.config.RoutesPath}, f.store.load().files...) {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
 	}
+	return paths
+}
 
-	var policies []Policy
-	for _, line := range strings.Split(string(data), "\n") {
-		if line == "" {
-			continue
+// LoadPolicies loads the policy and routes files into a new snapshot, shadow
+// policies failing to load are logged and never fail the enforcing ones
+func (f *authorizer) LoadPolicies() error {
+	if f.shadow != nil {
+		f.shadow.LoadPolicies()
+	}
+	return f.reload(true)
+}
+
+// reload loads the policy and routes files, unless force is set it does
+// nothing if they did not change since the current snapshot was loaded
+func (f *authorizer) reload(force bool) error {
+	err := f.store.update(func(old *snapshot) (*snapshot, error) {
+		paths := f.watchedPaths()
+		if !force && !filesChanged(f.store.seen, paths) {
+			return nil, nil
+		}
+		// stat before reading, so a change while loading is picked up next time
+		f.store.seen = statFiles(paths)
+		e, report := loadPolicies(&f.config)
+		if err := report.Err(); err != nil {
+			logReport(report)
+			return nil, err
 		}
-		var policy Policy
-		err := json.Unmarshal([]byte(line), &policy)
-		if err != nil {
-			logrus.Errorf("Failed to unmarshal policy %q %q", line, err.Error())
+		if !force && report.hash == old.hash && f.store.failure == nil {
+			return nil, nil
 		}
-		policies = append(policies, policy)
+		logReport(report)
+		logrus.Infof("Loaded %d %s from %d files, policy hash %s -> %s",
+			len(e.policies), f.kind(), len(report.Files), shortHash(old.hash), shortHash(report.hash))
+		return &snapshot{engine: e, files: report.Files, hash: report.hash, loadedAt: time.Now()}, nil
+	})
+	if err != nil {
+		s := f.store.load()
+		logrus.Warnf("Policy reload failed, keeping %d %s with hash %s", len(s.engine.policies), f.kind(), shortHash(s.hash))
+	}
+	return err
+}
+
+// kind names the policies of the authorizer in logs
+func (f *authorizer) kind() string {
+	if f.candidate {
+		return "shadow policies"
+	}
+	return "policies"
+}
+
+// Status reports the active policies and the last failed reload
+func (f *authorizer) Status() *PolicyStatus {
+	status := f.store.status()
+	if f.shadow != nil {
+		status.Shadow = f.shadow.status()
 	}
-	return policies, nil
+	return status
 }
 
 func (f *authorizer) GetPolicies() []Policy {
-	return f.policies
+	return f.store.load().engine.policies
+}
+
+// Decide decides an action requested by user directly
+func (f *authorizer) Decide(user, action string) *Decision {
+	return f.learn(f.store.load().engine.decide(user, action))
+}
+
+// DecideBatch decides all checks against the same policies
+func (f *authorizer) DecideBatch(checks []ActionCheck) []*Decision {
+	e := f.store.load().engine
+	decisions := make([]*Decision, len(checks))
+	for i, c := range checks {
+		decisions[i] = f.learn(e.decide(c.User, c.Action))
+	}
+	return decisions
+}
+
+// DecideRequest decides a plugin request, learning mode allows it
+func (f *authorizer) DecideRequest(request *authorization.Request) *Decision {
+	return f.learn(f.decideRequest(request))
+}
+
+// decideRequest decides a plugin request with the policies, requests to
+// unknown routes are logged once here in audit mode
+func (f *authorizer) decideRequest(request *authorization.Request) *Decision {
+	d := f.store.load().engine.decideRequest(request)
+	if d.Reason == ReasonUnknownAction && d.Allowed() && f.config.UnknownAction == UnknownActionAudit {
+		logrus.Warnf(
+			"Unknown action requested by user '%s', method: '%s', url: '%s'",
+			request.User,
+			request.RequestMethod,
+			request.RequestURI,
+		)
+	}
+	return d
+}
+
+// learn records the request of d in learning mode and allows it, decisions
+// are returned as they are otherwise
+func (f *authorizer) learn(d *Decision) *Decision {
+	if f.learner == nil {
+		return d
+	}
+	f.learner.record(d)
+	if d.Allowed() {
+		return d
+	}
+	logrus.Debugf("Learning mode allows: %s", d.Message)
+	learned := *d
+	learned.Effect = EffectAllow
+	learned.Reason = ReasonLearning
+	learned.Message = fmt.Sprintf("action '%s' allowed for user '%s' by learning mode", d.Action, d.User)
+	return &learned
 }
 
-func (f *authorizer) AuthZRequest(request *authorization.Request) *authorization.Response {
+// AuthZRequest decides a plugin request and returns the response along with
+// the decision, which tells the auditor the action without resolving it again
+func (f *authorizer) AuthZRequest(request *authorization.Request) (*authorization.Response, *Decision) {
 
 	logrus.Debugf("Received AuthZ request, method: '%s', url: '%s'", request.RequestMethod, request.RequestURI)
-	action := ParseRoute(request.RequestMethod, request.RequestURI)
-
-	response := &authorization.Response{}
-
-	for _, policy := range f.policies {
-		for _, user := range policy.Users {
-			if user == "" || user == request.User {
-				for _, policyActionPattern := range policy.Actions {
-					match, err := regexp.MatchString(policyActionPattern, action)
-					if err != nil {
-						logrus.Errorf(
-							"Failed to recognize action %q against policy %q error %q",
-							action,
-							policyActionPattern,
-							err.Error(),
-						)
-					}
-
-					if match {
-						if policy.Readonly && request.RequestMethod != "GET" {
-							response.Allow = false
-							response.Msg = fmt.Sprintf(
-								"action '%s' not allowed for user '%s' by readonly policy %s",
-								action,
-								request.User,
-								policy.Name,
-							)
-							return response
-						}
-						response.Allow = true
-						return response
-					}
-				}
-				response.Allow = false
-				response.Msg = fmt.Sprintf(
-					"action '%s' denied for user '%s' by policy '%s'",
-					action,
-					request.User,
-					policy.Name,
-				)
-				return response
-			}
-		}
+	decision := f.decideRequest(request)
+	logrus.Debugf("%s (reason: %s, policy source: %s)", decision.Message, decision.Reason, decision.Source)
+	// the shadow policies are compared with the policies, not learning mode
+	if f.shadow != nil {
+		f.shadow.compare(request, decision)
+	}
+	decision = f.learn(decision)
+	if decision.Allowed() {
+		return &authorization.Response{Allow: true}, decision
 	}
-	response.Allow = false
-	response.Msg = fmt.Sprintf(
-		"no policy applied (user: '%s' action: '%s')",
-		request.User,
-		action,
-	)
-	return response
+	return &authorization.Response{Allow: false, Msg: decision.Message}, decision
 }
 
 func (f *authorizer) AuthZResponse(request *authorization.Request) *authorization.Response {
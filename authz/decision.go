@@ -0,0 +1,216 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: decision engine shared by the plugin and isulad.auth requests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/sirupsen/logrus"
+)
+
+// Effect is the outcome of a decision
+type Effect string
+
+const (
+	// EffectAllow allows the request
+	EffectAllow Effect = "allow"
+	// EffectDeny denies the request
+	EffectDeny Effect = "deny"
+)
+
+// ReasonCode tells why a decision was made
+type ReasonCode string
+
+const (
+	// ReasonGranted means a rule of the applied policy granted the action
+	ReasonGranted ReasonCode = "granted"
+	// ReasonNotGranted means no rule of the applied policy granted the action
+	ReasonNotGranted ReasonCode = "not_granted"
+	// ReasonReadonly means a readonly policy granted an action that is not read
+	ReasonReadonly ReasonCode = "readonly"
+	// ReasonNoPolicy means no policy applies to the user
+	ReasonNoPolicy ReasonCode = "no_policy"
+	// ReasonUnknownAction means the request matches no route
+	ReasonUnknownAction ReasonCode = "unknown_action"
+	// ReasonUnversionedAPI means the request has no api version
+	ReasonUnversionedAPI ReasonCode = "unversioned_api"
+	// ReasonAPIVersion means the api version is not allowed
+	ReasonAPIVersion ReasonCode = "api_version"
+	// ReasonInvalidRule means the applied policy has rules that failed to match
+	ReasonInvalidRule ReasonCode = "invalid_rule"
+	// ReasonLearning means learning mode allowed an action the policies deny
+	ReasonLearning ReasonCode = "learning"
+)
+
+// Decision is the result of an authorization check
+type Decision struct {
+	Effect     Effect     `json:"effect"`               // Effect is allow or deny
+	User       string     `json:"user"`                 // User is the requesting user
+	Action     string     `json:"action"`               // Action is the isulad action
+	Class      string     `json:"class,omitempty"`      // Class is the route class of the action
+	Resource   string     `json:"resource,omitempty"`   // Resource is the object of the request, e.g. the container name
+	APIVersion string     `json:"apiVersion,omitempty"` // APIVersion is the api version prefix of the request uri
+	Policy     string     `json:"policy,omitempty"`     // Policy is the name of the applied policy
+	Rule       string     `json:"rule,omitempty"`       // Rule is the action entry or class that granted the action
+	Source     string     `json:"source,omitempty"`     // Source is the file and line the applied policy was loaded from
+	Reason     ReasonCode `json:"reason"`               // Reason tells why the decision was made
+	Message    string     `json:"message"`              // Message describes the decision
+}
+
+// ActionCheck is a user and action pair to decide
+type ActionCheck struct {
+	User   string
+	Action string
+}
+
+// Allowed reports whether the decision allows the request
+func (d *Decision) Allowed() bool {
+	return d.Effect == EffectAllow
+}
+
+// engine decides requests against a set of policies
+type engine struct {
+	router         *router
+	policies       []Policy
+	index          *policyIndex
+	unknownAction  UnknownActionMode
+	legacyActions  bool
+	minAPIVersion  string
+	maxAPIVersion  string
+	unversionedAPI string
+}
+
+func newEngine(config *Config, rt *router, policies []Policy) *engine {
+	return &engine{
+		router:         rt,
+		policies:       policies,
+		index:          newPolicyIndex(policies, rt, config.LegacyActions),
+		unknownAction:  config.UnknownAction,
+		legacyActions:  config.LegacyActions,
+		minAPIVersion:  config.MinAPIVersion,
+		maxAPIVersion:  config.MaxAPIVersion,
+		unversionedAPI: config.UnversionedAPI,
+	}
+}
+
+func deny(d *Decision, reason ReasonCode, format string, a ...interface{}) *Decision {
+	d.Effect = EffectDeny
+	d.Reason = reason
+	d.Message = fmt.Sprintf(format, a...)
+	return d
+}
+
+// decideRequest resolves a plugin request to its action and decides it
+func (e *engine) decideRequest(request *authorization.Request) *Decision {
+	route := e.router.resolve(request.RequestMethod, request.RequestURI, e.assumedAPIVersion())
+	d := &Decision{User: request.User, Action: route.Action, Class: route.Class, Resource: route.Resource, APIVersion: route.APIVersion}
+
+	// the daemon routes malformed version prefixes, whatever route matches
+	// them is unknown here, so they are denied in every unknown action mode
+	if route.APIVersion != "" && ParseAPIVersion(route.APIVersion) != nil {
+		return deny(d, ReasonAPIVersion,
+			"malformed api version '%s' denied for user '%s' (method: '%s' url: '%s')",
+			route.APIVersion, request.User, request.RequestMethod, request.RequestURI)
+	}
+
+	if route.Action == UnknownAction {
+		if e.unknownAction == UnknownActionAllow || e.unknownAction == UnknownActionAudit {
+			d.Effect = EffectAllow
+			d.Reason = ReasonUnknownAction
+			d.Message = fmt.Sprintf("unknown action allowed for user '%s' (method: '%s' url: '%s')",
+				request.User, request.RequestMethod, request.RequestURI)
+			return d
+		}
+		return deny(d, ReasonUnknownAction,
+			"unknown action denied for user '%s' (method: '%s' url: '%s')",
+			request.User,
+			request.RequestMethod,
+			request.RequestURI,
+		)
+	}
+
+	version := route.APIVersion
+	if version == "" {
+		switch e.unversionedAPI {
+		case "", UnversionedAPIAllow:
+		case UnversionedAPIDeny:
+			return deny(d, ReasonUnversionedAPI,
+				"unversioned api request denied for user '%s' action '%s'", request.User, route.Action)
+		default:
+			version = e.assumedAPIVersion()
+		}
+	}
+	if version != "" && !apiVersionInRange(version, e.minAPIVersion, e.maxAPIVersion) {
+		return deny(d, ReasonAPIVersion,
+			"api version '%s' not allowed for user '%s' action '%s'", version, request.User, route.Action)
+	}
+	return e.decideAction(d, version)
+}
+
+// assumedAPIVersion returns the api version assumed for unversioned
+// requests, empty unless one is configured
+func (e *engine) assumedAPIVersion() string {
+	switch e.unversionedAPI {
+	case "", UnversionedAPIAllow, UnversionedAPIDeny:
+		return ""
+	}
+	return e.unversionedAPI
+}
+
+// decide decides an action requested by user directly, as isulad.auth does
+func (e *engine) decide(user, action string) *Decision {
+	return e.decideAction(&Decision{User: user, Action: action, Class: e.router.actionClass(action)}, "")
+}
+
+// decideAction applies the first policy of the user to the action of d,
+// version is checked against the policy unless empty
+func (e *engine) decideAction(d *Decision, version string) *Decision {
+	policy := e.index.find(d.User)
+	if policy == nil {
+		return deny(d, ReasonNoPolicy, "no policy applied (user: '%s' action: '%s')", d.User, d.Action)
+	}
+	d.Policy = policy.Name
+	d.Source = policy.pos.String()
+
+	if version != "" && !apiVersionInRange(version, policy.MinAPIVersion, policy.MaxAPIVersion) {
+		return deny(d, ReasonAPIVersion,
+			"api version '%s' not allowed for user '%s' by policy '%s'", version, d.User, policy.Name)
+	}
+
+	_, known := e.router.actions[d.Action]
+	rule, err := policy.match(d.Action, d.Class, known)
+	if rule == "" {
+		if err != nil {
+			logrus.Errorf("[policy: %s] %v", policy.Name, err)
+			return deny(d, ReasonInvalidRule,
+				"action '%s' denied for user '%s' by invalid rule of policy '%s'", d.Action, d.User, policy.Name)
+		}
+		return deny(d, ReasonNotGranted,
+			"action '%s' denied for user '%s' by policy '%s'", d.Action, d.User, policy.Name)
+	}
+	d.Rule = rule
+
+	if policy.Readonly && d.Class != ClassRead {
+		return deny(d, ReasonReadonly,
+			"action '%s' not allowed for user '%s' by readonly policy %s", d.Action, d.User, policy.Name)
+	}
+	d.Effect = EffectAllow
+	d.Reason = ReasonGranted
+	d.Message = fmt.Sprintf("action '%s' allowed for user '%s' by policy '%s' rule '%s'", d.Action, d.User, policy.Name, rule)
+	return d
+}
+
+// classRulePrefix marks decision rules granted by a policy class
+const classRulePrefix = "class:"
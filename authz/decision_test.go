@@ -0,0 +1,282 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: decision latency benchmarks, run with
+//              go test -run NONE -bench . -benchmem ./authz
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	benchPolicies     = 1000
+	benchUsersPerPoly = 10
+)
+
+// newBenchAuthorizer loads 1k policies of 10 users each, 10k users in total,
+// the last policy applies to every other user
+func newBenchAuthorizer(b *testing.B) Authorizer {
+	entries := [][]string{
+		{"container_list", "container_inspect", "container_logs"},
+		{"container_*", "image_list"},
+		{"regex:container_(start|stop|restart)", "volume_.*"},
+		{"image_*", "network_*", "exact:isulad_info"},
+	}
+	var buf bytes.Buffer
+	for i := 0; i < benchPolicies; i++ {
+		p := Policy{Name: fmt.Sprintf("policy_%d", i), Actions: entries[i%len(entries)]}
+		if i == benchPolicies-1 {
+			p.Users = []string{""}
+		}
+		for u := 0; u < benchUsersPerPoly && i != benchPolicies-1; u++ {
+			p.Users = append(p.Users, fmt.Sprintf("user_%d", i*benchUsersPerPoly+u))
+		}
+		if i%5 == 0 {
+			p.Classes = []string{ClassRead}
+		}
+		line, err := json.Marshal(p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	p := writePolicyFile(b, buf.String())
+	f := NewAuthorizer(&Config{PolicyPath: p})
+	if err := f.LoadPolicies(); err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+var benchRequests = []struct {
+	method string
+	uri    string
+}{
+	{"GET", "/v1.40/containers/json?all=1"},
+	{"POST", "/v1.40/containers/abc/start"},
+	{"GET", "/v1.40/containers/abc/logs?follow=1"},
+	{"POST", "/v1.40/containers/abc/exec"},
+	{"GET", "/v1.40/images/registry.local:5000/team/app/json"},
+	{"DELETE", "/v1.40/volumes/data"},
+	{"GET", "/v1.40/info"},
+	{"POST", "/v1.40/networks/net/connect"},
+}
+
+func BenchmarkDecideRequest(b *testing.B) {
+	f := newBenchAuthorizer(b)
+	users := benchPolicies * benchUsersPerPoly
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := benchRequests[i%len(benchRequests)]
+		f.DecideRequest(&authorization.Request{
+			User:          fmt.Sprintf("user_%d", (i*7919)%users),
+			RequestMethod: r.method,
+			RequestURI:    r.uri,
+		})
+	}
+}
+
+func BenchmarkDecideAction(b *testing.B) {
+	f := newBenchAuthorizer(b)
+	users := benchPolicies * benchUsersPerPoly
+	actions := []string{"container_list", "container_start", "container_exec_create", "image_push", "volume_remove", "isulad_info"}
+	names := make([]string, users)
+	for i := range names {
+		names[i] = fmt.Sprintf("user_%d", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Decide(names[(i*7919)%users], actions[i%len(actions)])
+	}
+}
+
+func BenchmarkResolveRoute(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := benchRequests[i%len(benchRequests)]
+		ResolveRoute(r.method, r.uri)
+	}
+}
+
+func TestDecideUnknownAction(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["container_list"]}`+"\n")
+	cases := []struct {
+		mode  UnknownActionMode
+		user  string
+		allow bool
+	}{
+		{UnknownActionDeny, "alice", false},
+		{UnknownActionAllow, "alice", true},
+		{UnknownActionAllow, "nobody", true},
+		{UnknownActionAudit, "alice", true},
+		{UnknownActionAudit, "nobody", true},
+	}
+	for _, c := range cases {
+		f := NewAuthorizer(&Config{PolicyPath: p, UnknownAction: c.mode})
+		if err := f.LoadPolicies(); err != nil {
+			t.Fatal(err)
+		}
+		d := f.DecideRequest(&authorization.Request{User: c.user, RequestMethod: "POST", RequestURI: "/v1.40/containers/abc/foo"})
+		if d.Allowed() != c.allow || d.Reason != ReasonUnknownAction || d.Action != UnknownAction || d.Policy != "" {
+			t.Errorf("%s mode for %s: unexpected decision %+v", c.mode, c.user, d)
+		}
+	}
+}
+
+// TestDecideMalformedAPIVersion checks uris the daemon routes with a version
+// prefix that is not well formed are denied in every unknown action mode
+func TestDecideMalformedAPIVersion(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["container_list"]}`+"\n")
+	for _, mode := range []UnknownActionMode{UnknownActionDeny, UnknownActionAllow, UnknownActionAudit} {
+		f := NewAuthorizer(&Config{PolicyPath: p, UnknownAction: mode})
+		if err := f.LoadPolicies(); err != nil {
+			t.Fatal(err)
+		}
+		for _, uri := range []string{"/v1..40/containers/abc/exec", "/v1.40./containers/json", "/v./containers/json"} {
+			d := f.DecideRequest(&authorization.Request{User: "nobody", RequestMethod: "POST", RequestURI: uri})
+			if d.Allowed() || d.Reason != ReasonAPIVersion {
+				t.Errorf("%s mode for %s: unexpected decision %+v", mode, uri, d)
+			}
+		}
+		d := f.DecideRequest(&authorization.Request{User: "alice", RequestMethod: "GET", RequestURI: "/v1.40.1/containers/json"})
+		if !d.Allowed() || d.Action != "container_list" || d.APIVersion != "1.40.1" {
+			t.Errorf("%s mode: unexpected decision for a three part version %+v", mode, d)
+		}
+	}
+}
+
+func TestUnknownActionAuditedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logrus.SetOutput(&buf)
+	defer logrus.SetOutput(os.Stderr)
+
+	p := writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["container_list"]}`+"\n")
+	f := NewAuthorizer(&Config{PolicyPath: p, ShadowPolicyPath: p, UnknownAction: UnknownActionAudit})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	resp, d := f.AuthZRequest(&authorization.Request{User: "alice", RequestMethod: "POST", RequestURI: "/v1.40/containers/abc/foo"})
+	if !resp.Allow || d.Action != UnknownAction {
+		t.Fatalf("unexpected response %+v, decision %+v", resp, d)
+	}
+	if n := strings.Count(buf.String(), "Unknown action requested"); n != 1 {
+		t.Errorf("unknown action logged %d times, want once:\n%s", n, buf.String())
+	}
+}
+
+func TestDecideAPIVersion(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"old","users":["alice"],"actions":["glob:*"],"maxApiVersion":"1.30"}`+"\n"+
+		`{"name":"new","users":["bob"],"actions":["glob:*"],"minApiVersion":"1.35"}`+"\n"+
+		`{"name":"any","users":["carol"],"actions":["glob:*"]}`+"\n")
+	cases := []struct {
+		min, max, unversioned string
+		user, method, uri     string
+		allow                 bool
+		reason                ReasonCode
+		action                string
+	}{
+		{"", "", "", "carol", "GET", "/v1.10/info", true, ReasonGranted, "isulad_info"},
+		{"1.24", "1.40", "", "carol", "GET", "/v1.23/info", false, ReasonAPIVersion, "isulad_info"},
+		{"1.24", "1.40", "", "carol", "GET", "/v1.41/info", false, ReasonAPIVersion, "isulad_info"},
+		{"1.24", "1.40", "", "carol", "GET", "/v1.40/info", true, ReasonGranted, "isulad_info"},
+		{"1.24", "1.40", "", "carol", "GET", "/info", true, ReasonGranted, "isulad_info"},
+		{"1.24", "1.40", UnversionedAPIDeny, "carol", "GET", "/info", false, ReasonUnversionedAPI, "isulad_info"},
+		{"1.24", "1.40", "1.20", "carol", "GET", "/info", false, ReasonAPIVersion, "isulad_info"},
+		{"1.24", "1.40", "1.30", "carol", "GET", "/info", true, ReasonGranted, "isulad_info"},
+		{"", "", "", "alice", "GET", "/v1.30/info", true, ReasonGranted, "isulad_info"},
+		{"", "", "", "alice", "GET", "/v1.31/info", false, ReasonAPIVersion, "isulad_info"},
+		{"", "", "1.40", "alice", "GET", "/info", false, ReasonAPIVersion, "isulad_info"},
+		{"", "", "", "bob", "GET", "/v1.30/info", false, ReasonAPIVersion, "isulad_info"},
+		{"", "", "1.40", "bob", "GET", "/info", true, ReasonGranted, "isulad_info"},
+		// the assumed version selects the routes of that version
+		{"", "", "", "carol", "POST", "/containers/abc/copy", true, ReasonGranted, "container_copyfiles"},
+		{"", "", "1.23", "carol", "POST", "/containers/abc/copy", true, ReasonGranted, "container_copyfiles"},
+		{"", "", "1.24", "carol", "POST", "/containers/abc/copy", false, ReasonUnknownAction, UnknownAction},
+	}
+	for _, c := range cases {
+		config := &Config{PolicyPath: p, MinAPIVersion: c.min, MaxAPIVersion: c.max, UnversionedAPI: c.unversioned}
+		if err := config.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		f := NewAuthorizer(config)
+		if err := f.LoadPolicies(); err != nil {
+			t.Fatal(err)
+		}
+		d := f.DecideRequest(&authorization.Request{User: c.user, RequestMethod: c.method, RequestURI: c.uri})
+		if d.Allowed() != c.allow || d.Reason != c.reason || d.Action != c.action {
+			t.Errorf("%s %s %s (min %q max %q unversioned %q): unexpected decision %+v",
+				c.user, c.method, c.uri, c.min, c.max, c.unversioned, d)
+		}
+	}
+}
+
+func TestConfigValidateAPIVersions(t *testing.T) {
+	cases := []struct {
+		min, max, unversioned string
+		valid                 bool
+	}{
+		{"1.24", "1.40", "", true},
+		{"1.40", "1.40", "", true},
+		{"1.41", "1.40", "", false},
+		{"1.9", "1.10", "", true},
+		{"1.x", "", "", false},
+		{"", "", "1.30", true},
+		{"", "", "sometimes", false},
+	}
+	for _, c := range cases {
+		config := &Config{PolicyPath: "policy.json", MinAPIVersion: c.min, MaxAPIVersion: c.max, UnversionedAPI: c.unversioned}
+		if err := config.Validate(); (err == nil) != c.valid {
+			t.Errorf("min %q max %q unversioned %q: got error %v, want valid %t", c.min, c.max, c.unversioned, err, c.valid)
+		}
+	}
+}
+
+func TestDecideReadonlyByClass(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"viewers","users":["bob"],"actions":["glob:container_*"],"readonly":true}`+"\n"+
+		`{"name":"readers","users":["carol"],"classes":["read"]}`+"\n")
+	f := NewAuthorizer(&Config{PolicyPath: p})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		user, method, uri string
+		allow             bool
+		reason            ReasonCode
+	}{
+		{"bob", "GET", "/containers/abc/logs", true, ReasonGranted},
+		{"bob", "HEAD", "/containers/abc/archive", true, ReasonGranted},
+		{"bob", "POST", "/containers/abc/start", false, ReasonReadonly},
+		{"bob", "GET", "/containers/abc/export", false, ReasonReadonly},
+		{"bob", "GET", "/images/json", false, ReasonNotGranted},
+		{"carol", "GET", "/images/json", true, ReasonGranted},
+		{"carol", "GET", "/containers/abc/export", false, ReasonNotGranted},
+		{"carol", "POST", "/containers/abc/kill", false, ReasonNotGranted},
+	}
+	for _, c := range cases {
+		d := f.DecideRequest(&authorization.Request{User: c.user, RequestMethod: c.method, RequestURI: c.uri})
+		if d.Allowed() != c.allow || d.Reason != c.reason {
+			t.Errorf("%s %s %s: unexpected decision %+v", c.user, c.method, c.uri, d)
+		}
+	}
+}
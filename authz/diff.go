@@ -0,0 +1,139 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: semantic diff of the permissions of two policy sets
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is how a permission changed between two policy sets
+type ChangeKind string
+
+const (
+	// ChangeGain means the user may do the action only with the new policies
+	ChangeGain ChangeKind = "gains"
+	// ChangeLoss means the user may do the action only with the old policies
+	ChangeLoss ChangeKind = "loses"
+	// ChangeConditions means the action stays allowed with other conditions
+	ChangeConditions ChangeKind = "changes"
+)
+
+// PermissionChange is a decision that differs between two policy sets
+type PermissionChange struct {
+	User   string      `json:"user"`
+	Action string      `json:"action"`
+	Kind   ChangeKind  `json:"kind"`
+	Old    *MatrixCell `json:"old"`
+	New    *MatrixCell `json:"new"`
+}
+
+// deniedCell stands for actions missing from a matrix
+var deniedCell = &MatrixCell{Reason: ReasonUnknownAction}
+
+// lookup returns the cell of user and action, users not in the matrix get
+// the permissions of AnyUser
+func (m *PermissionMatrix) lookup(user, action string) *MatrixCell {
+	if cell := m.Cell(user, action); cell != nil {
+		return cell
+	}
+	if cell := m.Cell(AnyUser, action); cell != nil {
+		return cell
+	}
+	return deniedCell
+}
+
+// DiffPermissions compares the decisions of two matrices over the union of
+// their users and actions, changes are sorted by user, kind and action
+func DiffPermissions(from, to *PermissionMatrix) []*PermissionChange {
+	users := unionStrings(from.Users, to.Users)
+	var actions []string
+	for _, m := range []*PermissionMatrix{from, to} {
+		for _, a := range m.Actions {
+			actions = append(actions, a.Name)
+		}
+	}
+	actions = unionStrings(actions)
+
+	var changes []*PermissionChange
+	for _, u := range users {
+		for _, a := range actions {
+			o, n := from.lookup(u, a), to.lookup(u, a)
+			kind := ChangeKind("")
+			switch {
+			case !o.Allow && n.Allow:
+				kind = ChangeGain
+			case o.Allow && !n.Allow:
+				kind = ChangeLoss
+			case o.Allow && n.Allow && strings.Join(o.Conditions, ", ") != strings.Join(n.Conditions, ", "):
+				kind = ChangeConditions
+			default:
+				continue
+			}
+			changes = append(changes, &PermissionChange{User: u, Action: a, Kind: kind, Old: o, New: n})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].User != changes[j].User {
+			return changes[i].User < changes[j].User
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// unionStrings returns the distinct strings of all lists, sorted
+func unionStrings(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var union []string
+	for _, l := range lists {
+		for _, s := range l {
+			if !seen[s] {
+				seen[s] = true
+				union = append(union, s)
+			}
+		}
+	}
+	sort.Strings(union)
+	return union
+}
+
+// SummarizeChanges describes changes one line per user and kind, such as
+// "bob gains container_exec_create, container_exec_start"
+func SummarizeChanges(changes []*PermissionChange) []string {
+	var lines []string
+	for i := 0; i < len(changes); {
+		c := changes[i]
+		var actions []string
+		j := i
+		for ; j < len(changes) && changes[j].User == c.User && changes[j].Kind == c.Kind; j++ {
+			a := changes[j].Action
+			if c.Kind == ChangeConditions {
+				a = fmt.Sprintf("%s (%s -> %s)", a, conditionsText(changes[j].Old), conditionsText(changes[j].New))
+			}
+			actions = append(actions, a)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s", c.User, c.Kind, strings.Join(actions, ", ")))
+		i = j
+	}
+	return lines
+}
+
+func conditionsText(cell *MatrixCell) string {
+	if len(cell.Conditions) == 0 {
+		return "always"
+	}
+	return strings.Join(cell.Conditions, ", ")
+}
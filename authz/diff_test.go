@@ -0,0 +1,55 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: permission diff tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffPermissions(t *testing.T) {
+	matrix := func(lines ...string) *PermissionMatrix {
+		p := writePolicyFile(t, strings.Join(lines, "\n")+"\n")
+		m, err := NewPermissionMatrix(&Config{PolicyPath: p})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	old := matrix(
+		`{"name":"alice","users":["alice"],"actions":["container_list","container_start"]}`,
+		`{"name":"carol","users":["carol"],"actions":["image_list","image_push"]}`,
+		`{"name":"rest","users":[""],"actions":["image_list"]}`,
+	)
+	updated := matrix(
+		`{"name":"alice","users":["alice"],"actions":["container_list","container_start"],"maxApiVersion":"1.40"}`,
+		`{"name":"bob","users":["bob"],"actions":["image_list","container_exec_create"]}`,
+		`{"name":"carol","users":["carol"],"actions":["image_list"]}`,
+		`{"name":"rest","users":[""],"actions":["image_list"]}`,
+	)
+
+	// bob is only named in the new policies, before they were any user
+	want := []string{
+		"alice changes container_list (always -> api <= 1.40), container_start (always -> api <= 1.40)",
+		"bob gains container_exec_create",
+		"carol loses image_push",
+	}
+	if got := SummarizeChanges(DiffPermissions(old, updated)); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected changes\n%s", strings.Join(got, "\n"))
+	}
+	if changes := DiffPermissions(updated, updated); len(changes) != 0 {
+		t.Errorf("expected no changes, got %d", len(changes))
+	}
+}
@@ -20,12 +20,17 @@ type Authorizer interface {
 	Init() error
 	LoadPolicies() error
 	GetPolicies() []Policy
-	AuthZRequest(req *authorization.Request) *authorization.Response
+	Status() *PolicyStatus
+	Decide(user, action string) *Decision
+	DecideBatch(checks []ActionCheck) []*Decision
+	DecideRequest(req *authorization.Request) *Decision
+	AuthZRequest(req *authorization.Request) (*authorization.Response, *Decision)
 	AuthZResponse(req *authorization.Request) *authorization.Response
+	Close() error
 }
 
 // Auditor audits the request and response sent from/to isulad daemon
 type Auditor interface {
-	AuditRequest(req *authorization.Request, resp *authorization.Response) error
+	AuditRequest(req *authorization.Request, resp *authorization.Response, decision *Decision) error
 	AuditResponse(req *authorization.Request, resp *authorization.Response) error
 }
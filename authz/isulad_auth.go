@@ -0,0 +1,157 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: json protocol of isulad.auth requests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// IsuladAuthVersion is the version of the isulad.auth json protocol
+const IsuladAuthVersion = "1"
+
+// MaxBatchChecks is the maximum number of checks in a batch request
+const MaxBatchChecks = 1024
+
+// IsuladAuthRequest is a json isulad.auth request. Resource, Attributes and
+// AuthMethod are accepted for logging only, policies cannot restrict them
+// yet so they never change a decision.
+type IsuladAuthRequest struct {
+	Version    string            `json:"version"`              // Version is the protocol version
+	RequestID  string            `json:"requestId,omitempty"`  // RequestID identifies the request, generated if empty
+	User       string            `json:"user"`                 // User is the requesting user
+	Action     string            `json:"action"`               // Action is the isulad action
+	Resource   string            `json:"resource,omitempty"`   // Resource is the container, image or other object acted on, ignored
+	Attributes map[string]string `json:"attributes,omitempty"` // Attributes are additional request attributes, ignored
+	AuthMethod string            `json:"authMethod,omitempty"` // AuthMethod is how isulad authenticated the user, e.g. tls, ignored
+}
+
+// IsuladAuthResponse is a json isulad.auth response
+type IsuladAuthResponse struct {
+	Version   string     `json:"version"`          // Version is the protocol version
+	RequestID string     `json:"requestId"`        // RequestID identifies the request
+	Allow     bool       `json:"allow"`            // Allow tells whether the request is allowed
+	Reason    ReasonCode `json:"reason,omitempty"` // Reason tells why the decision was made
+	Policy    string     `json:"policy,omitempty"` // Policy is the name of the applied policy
+	Rule      string     `json:"rule,omitempty"`   // Rule is the action entry or class that granted the action
+	Source    string     `json:"source,omitempty"` // Source is the file and line the applied policy was loaded from
+	Msg       string     `json:"msg,omitempty"`    // Msg describes the decision
+	Err       string     `json:"err,omitempty"`    // Err is set if the request could not be decided
+}
+
+// ParseIsuladAuthRequest decodes and validates a json isulad.auth request
+func ParseIsuladAuthRequest(body []byte) (*IsuladAuthRequest, error) {
+	req := &IsuladAuthRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+func (req *IsuladAuthRequest) validate() error {
+	if req.RequestID == "" {
+		req.RequestID = newRequestID()
+	}
+	if req.Version != IsuladAuthVersion {
+		return fmt.Errorf("unsupported isulad.auth version %q", req.Version)
+	}
+	if req.Action == "" {
+		return fmt.Errorf("action is empty")
+	}
+	return nil
+}
+
+// IsuladBatchRequest is a json isulad.auth.batch request
+type IsuladBatchRequest struct {
+	Version   string               `json:"version"`             // Version is the protocol version
+	RequestID string               `json:"requestId,omitempty"` // RequestID identifies the batch, generated if empty
+	Checks    []*IsuladAuthRequest `json:"checks"`              // Checks are the requests to decide
+}
+
+// IsuladBatchResponse is a json isulad.auth.batch response
+type IsuladBatchResponse struct {
+	Version   string                `json:"version"`       // Version is the protocol version
+	RequestID string                `json:"requestId"`     // RequestID identifies the batch
+	Results   []*IsuladAuthResponse `json:"results"`       // Results are the responses in the order of the checks
+	Err       string                `json:"err,omitempty"` // Err is set if the batch could not be decided
+}
+
+// ParseIsuladBatchRequest decodes and validates a json isulad.auth.batch
+// request. Checks inherit the batch version if they have none, invalid
+// checks are reported by CheckErrors rather than failing the whole batch.
+func ParseIsuladBatchRequest(body []byte) (*IsuladBatchRequest, error) {
+	req := &IsuladBatchRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		return nil, err
+	}
+	if req.RequestID == "" {
+		req.RequestID = newRequestID()
+	}
+	if req.Version != IsuladAuthVersion {
+		return req, fmt.Errorf("unsupported isulad.auth version %q", req.Version)
+	}
+	if len(req.Checks) > MaxBatchChecks {
+		return req, fmt.Errorf("too many checks %d, at most %d are allowed", len(req.Checks), MaxBatchChecks)
+	}
+	for i, check := range req.Checks {
+		if check == nil {
+			req.Checks[i] = &IsuladAuthRequest{}
+			check = req.Checks[i]
+		}
+		if check.Version == "" {
+			check.Version = req.Version
+		}
+		if check.RequestID == "" {
+			check.RequestID = fmt.Sprintf("%s-%d", req.RequestID, i)
+		}
+	}
+	return req, nil
+}
+
+// CheckErrors validates the checks of a batch, the error of a valid check is nil
+func (req *IsuladBatchRequest) CheckErrors() []error {
+	errs := make([]error, len(req.Checks))
+	for i, check := range req.Checks {
+		errs[i] = check.validate()
+	}
+	return errs
+}
+
+// NewIsuladAuthResponse converts a decision to the response of req
+func NewIsuladAuthResponse(req *IsuladAuthRequest, d *Decision) *IsuladAuthResponse {
+	return &IsuladAuthResponse{
+		Version:   IsuladAuthVersion,
+		RequestID: req.RequestID,
+		Allow:     d.Allowed(),
+		Reason:    d.Reason,
+		Policy:    d.Policy,
+		Rule:      d.Rule,
+		Source:    d.Source,
+		Msg:       d.Message,
+	}
+}
+
+// newRequestID returns a random request id
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
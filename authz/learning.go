@@ -0,0 +1,248 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: learning mode recording requests and proposing policies from them
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LearnedRequest is a distinct request seen in learning mode
+type LearnedRequest struct {
+	User     string `json:"user"`
+	Action   string `json:"action"`
+	Class    string `json:"class,omitempty"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// learningRecord is a line of the learning log
+type learningRecord struct {
+	Time string `json:"time"`
+	LearnedRequest
+}
+
+// learner records the distinct requests seen in learning mode
+type learner struct {
+	mu   sync.Mutex
+	seen map[LearnedRequest]bool
+	out  io.Writer // out is the learning log, nothing is recorded until it is opened
+}
+
+func newLearner(config *Config) *learner {
+	if config.LearningPath == "" {
+		return nil
+	}
+	return &learner{seen: make(map[LearnedRequest]bool)}
+}
+
+// init opens the learning log for appending
+func (l *learner) init(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.out = f
+	l.mu.Unlock()
+	return nil
+}
+
+// record appends the request of d to the learning log unless it was seen
+// since the daemon started, requests without action are never recorded
+func (l *learner) record(d *Decision) {
+	if d.Action == "" {
+		return
+	}
+	req := LearnedRequest{User: d.User, Action: d.Action, Class: d.Class, Resource: d.Resource}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.out == nil || l.seen[req] {
+		return
+	}
+	data, err := json.Marshal(learningRecord{Time: time.Now().UTC().Format(time.RFC3339), LearnedRequest: req})
+	if err != nil {
+		logrus.Errorf("Failed to marshal learned request: %v", err)
+		return
+	}
+	if _, err := l.out.Write(append(data, '\n')); err != nil {
+		logrus.Errorf("Failed to write learning log: %v", err)
+		return
+	}
+	l.seen[req] = true
+}
+
+// ReadLearningLog reads the requests of a learning log, name is used in
+// errors. Requests recorded more than once are returned once.
+func ReadLearningLog(r io.Reader, name string) ([]*LearnedRequest, error) {
+	var requests []*LearnedRequest
+	seen := make(map[LearnedRequest]bool)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record learningRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", name, line, err)
+		}
+		if record.Action == "" {
+			return nil, fmt.Errorf("%s:%d: record has no action", name, line)
+		}
+		if !seen[record.LearnedRequest] {
+			seen[record.LearnedRequest] = true
+			req := record.LearnedRequest
+			requests = append(requests, &req)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read learning log %s: %v", name, err)
+	}
+	return requests, nil
+}
+
+// LearnOptions generalize the policies proposed from learned requests
+type LearnOptions struct {
+	GroupUsers bool // GroupUsers lets users with the same actions share a policy
+	GlobMin    int  // GlobMin collapses actions of a prefix such as container_ into a glob once a user did that many, 0 never
+}
+
+// learnedPolicyPrefix prefixes the names of learned policies
+const learnedPolicyPrefix = "learned-"
+
+// LearnPolicies proposes a policy per user allowing the learned actions of
+// the user and nothing else, unless generalized by options. Policies of
+// users that only read are readonly, so globs do not grant writes. The
+// policy of the empty user comes last as it applies to every user.
+func LearnPolicies(requests []*LearnedRequest, options LearnOptions) []Policy {
+	actions := make(map[string]map[string]string)
+	for _, r := range requests {
+		if actions[r.User] == nil {
+			actions[r.User] = make(map[string]string)
+		}
+		actions[r.User][r.Action] = r.Class
+	}
+	users := make([]string, 0, len(actions))
+	for u := range actions {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		// the empty user sorts last
+		return users[j] == "" || (users[i] != "" && users[i] < users[j])
+	})
+
+	var policies []Policy
+	byEntries := make(map[string]int)
+	for _, u := range users {
+		entries, readonly := learnedEntries(actions[u], options.GlobMin)
+		key := fmt.Sprintf("%t %s", readonly, strings.Join(entries, " "))
+		if i, ok := byEntries[key]; ok && options.GroupUsers && u != "" {
+			policies[i].Users = append(policies[i].Users, u)
+			continue
+		}
+		name := learnedPolicyPrefix + u
+		if u == "" {
+			name = learnedPolicyPrefix + "any"
+		}
+		byEntries[key] = len(policies)
+		policies = append(policies, Policy{Name: name, Users: []string{u}, Actions: entries, Readonly: readonly})
+	}
+	return policies
+}
+
+// learnedEntries returns the sorted action entries granting actions, and
+// whether all of them are read actions. Entries are explicitly exact or
+// glob, so the policies do not depend on how bare entries are read.
+func learnedEntries(actions map[string]string, globMin int) ([]string, bool) {
+	readonly := true
+	prefixes := make(map[string][]string)
+	for action, class := range actions {
+		readonly = readonly && class == ClassRead
+		prefix := action
+		if i := strings.Index(action, "_"); i > 0 && action != UnknownAction {
+			prefix = action[:i+1]
+		}
+		prefixes[prefix] = append(prefixes[prefix], action)
+	}
+	var entries []string
+	for prefix, names := range prefixes {
+		if globMin > 0 && len(names) >= globMin && strings.HasSuffix(prefix, "_") {
+			entries = append(entries, globPrefix+prefix+"*")
+			continue
+		}
+		for _, name := range names {
+			entries = append(entries, exactPrefix+name)
+		}
+	}
+	sort.Strings(entries)
+	return entries, readonly
+}
+
+var (
+	// resourceIDRegexp matches container, image and exec ids
+	resourceIDRegexp = regexp.MustCompile(`\b[0-9a-f]{12,64}\b`)
+	// resourceNumberRegexp matches numbers in names such as web-1
+	resourceNumberRegexp = regexp.MustCompile(`[0-9]+`)
+)
+
+// GeneralizeResource turns a resource into a pattern by replacing ids and
+// numbers with *, so web-1 and web-2 both become web-*
+func GeneralizeResource(resource string) string {
+	resource = resourceIDRegexp.ReplaceAllString(resource, "*")
+	return resourceNumberRegexp.ReplaceAllString(resource, "*")
+}
+
+// SummarizeResources lists the generalized resources of each user and
+// action, one line per pair such as "alice container_start web-*, db"
+func SummarizeResources(requests []*LearnedRequest) []string {
+	patterns := make(map[string]map[string]bool)
+	for _, r := range requests {
+		if r.Resource == "" {
+			continue
+		}
+		key := fmt.Sprintf("%s %s", learnedUserName(r.User), r.Action)
+		if patterns[key] == nil {
+			patterns[key] = make(map[string]bool)
+		}
+		patterns[key][GeneralizeResource(r.Resource)] = true
+	}
+	var lines []string
+	for key, set := range patterns {
+		var resources []string
+		for p := range set {
+			resources = append(resources, p)
+		}
+		sort.Strings(resources)
+		lines = append(lines, key+" "+strings.Join(resources, ", "))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// learnedUserName quotes the empty user for display
+func learnedUserName(user string) string {
+	if user == "" {
+		return `""`
+	}
+	return user
+}
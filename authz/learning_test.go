@@ -0,0 +1,118 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: learning mode tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+func TestLearningMode(t *testing.T) {
+	var log bytes.Buffer
+	f := NewAuthorizer(&Config{
+		PolicyPath:   writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["container_list"]}`+"\n"),
+		LearningPath: "learning.log",
+	}).(*authorizer)
+	f.learner.out = &log
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []struct{ user, method, uri string }{
+		{"alice", "GET", "/v1.40/containers/json"},
+		{"alice", "POST", "/v1.40/containers/web-1/start"},
+		{"alice", "POST", "/v1.40/containers/web-1/start"},
+		{"bob", "GET", "/v1.40/images/busybox/json"},
+	} {
+		resp, _ := f.AuthZRequest(&authorization.Request{User: r.user, RequestMethod: r.method, RequestURI: r.uri})
+		if !resp.Allow {
+			t.Fatalf("learning mode denied %s %s: %+v", r.user, r.uri, resp)
+		}
+	}
+
+	d := f.Decide("carol", "image_push")
+	if !d.Allowed() || d.Reason != ReasonLearning {
+		t.Errorf("learning mode decided %+v", d)
+	}
+	if d := f.Decide("alice", "container_list"); d.Reason != ReasonGranted {
+		t.Errorf("learning mode changed a granted decision %+v", d)
+	}
+	f.Decide("bob", "")
+	for _, d := range f.DecideBatch([]ActionCheck{{User: "carol", Action: "image_delete"}, {User: "bob"}}) {
+		if !d.Allowed() || d.Reason != ReasonLearning {
+			t.Errorf("learning mode decided %+v", d)
+		}
+	}
+
+	requests, err := ReadLearningLog(&log, "learning.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*LearnedRequest{
+		{User: "alice", Action: "container_list", Class: ClassRead},
+		{User: "alice", Action: "container_start", Class: ClassWrite, Resource: "web-1"},
+		{User: "bob", Action: "image_inspect", Class: ClassRead, Resource: "busybox"},
+		{User: "carol", Action: "image_push", Class: ClassWrite},
+		{User: "carol", Action: "image_delete", Class: ClassWrite},
+	}
+	if !reflect.DeepEqual(requests, want) {
+		for _, r := range requests {
+			t.Logf("%+v", r)
+		}
+		t.Errorf("unexpected learned requests")
+	}
+}
+
+func TestLearnPolicies(t *testing.T) {
+	requests := []*LearnedRequest{
+		{User: "", Action: "isulad_ping", Class: ClassRead},
+		{User: "bob", Action: "container_list", Class: ClassRead},
+		{User: "bob", Action: "container_logs", Class: ClassRead, Resource: "web-12"},
+		{User: "alice", Action: "container_list", Class: ClassRead},
+		{User: "alice", Action: "container_logs", Class: ClassRead, Resource: "web-3"},
+		{User: "carol", Action: "container_list", Class: ClassRead},
+		{User: "carol", Action: "container_start", Class: ClassWrite, Resource: "0123456789abcdef"},
+	}
+	policies := LearnPolicies(requests, LearnOptions{})
+	var names []string
+	for _, p := range policies {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"learned-alice", "learned-bob", "learned-carol", "learned-any"}) {
+		t.Fatalf("unexpected policies %v", names)
+	}
+	if p := policies[2]; p.Readonly || !reflect.DeepEqual(p.Actions, []string{"exact:container_list", "exact:container_start"}) {
+		t.Errorf("unexpected policy %+v", p)
+	}
+
+	policies = LearnPolicies(requests, LearnOptions{GroupUsers: true, GlobMin: 2})
+	if len(policies) != 3 {
+		t.Fatalf("expected 3 policies, got %+v", policies)
+	}
+	if p := policies[0]; !reflect.DeepEqual(p.Users, []string{"alice", "bob"}) || !p.Readonly ||
+		!reflect.DeepEqual(p.Actions, []string{"glob:container_*"}) {
+		t.Errorf("unexpected grouped policy %+v", p)
+	}
+
+	want := []string{
+		"alice container_logs web-*",
+		"bob container_logs web-*",
+		"carol container_start *",
+	}
+	if lines := SummarizeResources(requests); !reflect.DeepEqual(lines, want) {
+		t.Errorf("unexpected resources %v", lines)
+	}
+}
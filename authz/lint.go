@@ -0,0 +1,127 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: lint policies that load but likely do not do what was intended
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dangerousClasses are the route classes whose actions give access to the
+// processes, files or credentials of containers and the daemon
+var dangerousClasses = map[string]bool{
+	ClassExec:          true,
+	ClassAdmin:         true,
+	ClassSensitiveRead: true,
+}
+
+// LintPolicies validates the configured policies like ValidatePolicies and,
+// if they are valid, adds warnings for shadowed policies, the empty user,
+// patterns matching no action and dangerous actions granted broadly
+func LintPolicies(config *Config) *ValidationReport {
+	e, report := loadPolicies(config)
+	if e != nil {
+		e.lint(report)
+	}
+	sort.SliceStable(report.Warnings, func(i, j int) bool {
+		a, b := report.Warnings[i], report.Warnings[j]
+		return a.File < b.File || (a.File == b.File && a.Line < b.Line)
+	})
+	return report
+}
+
+// lint checks the policies in order and skips the ones that never apply
+func (e *engine) lint(report *ValidationReport) {
+	for _, cp := range e.index.policies {
+		if !e.lintShadowed(cp, report) {
+			continue
+		}
+		if cp.listsUser("") {
+			report.warnf(cp.pos, cp.Name, "empty user applies the policy to every user not listed by an earlier policy")
+		}
+		for _, entry := range cp.entries {
+			if entry.re != nil && !e.router.matchesAny(entry.re) {
+				report.warnf(cp.pos, cp.Name, "action pattern %q matches no known action", entry.entry)
+			}
+		}
+		e.lintDangerous(cp, report)
+	}
+}
+
+// lintShadowed warns about a policy whose users earlier policies all apply
+// to under first match order, it returns false if the policy never applies
+func (e *engine) lintShadowed(cp *compiledPolicy, report *ValidationReport) bool {
+	if len(cp.Users) == 0 {
+		report.warnf(cp.pos, cp.Name, "policy lists no users and never applies")
+		return false
+	}
+	var shadowed []string
+	for _, u := range cp.Users {
+		if first := e.index.find(u); first != cp {
+			shadowed = append(shadowed, fmt.Sprintf("%q by %q", u, first.Name))
+		}
+	}
+	if len(shadowed) == len(cp.Users) {
+		report.warnf(cp.pos, cp.Name, "policy is shadowed by earlier policies and never applies: %s", strings.Join(shadowed, ", "))
+		return false
+	}
+	// users shadowed in a policy that still applies are listed twice, which
+	// validation warns about
+	return true
+}
+
+// lintDangerous warns about actions of dangerous classes that a policy
+// grants to every user, or by pattern or class rather than by name
+func (e *engine) lintDangerous(cp *compiledPolicy, report *ValidationReport) {
+	rules := make(map[string][]string)
+	for action, class := range e.router.actions {
+		if !dangerousClasses[class] || (cp.Readonly && class != ClassRead) {
+			continue
+		}
+		rule, _ := cp.match(action, class, true)
+		if rule == "" {
+			continue
+		}
+		if !cp.listsUser("") && (rule == action || rule == exactPrefix+action) {
+			continue
+		}
+		rules[rule] = append(rules[rule], action)
+	}
+
+	var keys []string
+	for rule := range rules {
+		keys = append(keys, rule)
+	}
+	sort.Strings(keys)
+	for _, rule := range keys {
+		actions := rules[rule]
+		sort.Strings(actions)
+		if cp.listsUser("") {
+			report.warnf(cp.pos, cp.Name, "dangerous actions granted to every user by %q: %s", rule, strings.Join(actions, ", "))
+			continue
+		}
+		report.warnf(cp.pos, cp.Name, "dangerous actions granted by %q rather than by name: %s", rule, strings.Join(actions, ", "))
+	}
+}
+
+// listsUser reports whether the policy lists user
+func (cp *compiledPolicy) listsUser(user string) bool {
+	for _, u := range cp.Users {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
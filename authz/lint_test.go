@@ -0,0 +1,55 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy lint tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLintPolicies(t *testing.T) {
+	p := writePolicyFile(t, strings.Join([]string{
+		`{"name":"admins","users":["alice"],"actions":["container_*"]}`,
+		`{"name":"ops","users":["bob"],"actions":["container_exec_create","network_zz*"]}`,
+		`{"name":"rest","users":[""],"actions":["image_list","container_attach"]}`,
+		`{"name":"carol","users":["carol","dave"],"actions":["image_list"]}`,
+		`{"name":"again","users":["alice"],"actions":["image_list"]}`,
+		`{"name":"all","users":["erin"],"actions":["image_list"]}`,
+	}, "\n")+"\n")
+	report := LintPolicies(&Config{PolicyPath: p})
+	if len(report.Errors) != 0 {
+		t.Fatalf("unexpected errors %v", report.Errors)
+	}
+	var got []string
+	for _, w := range report.Warnings {
+		got = append(got, w.Location()+" "+w.Message())
+	}
+	loc := func(line string) string { return p + ":" + line + " " }
+	want := []string{
+		loc("1") + "[policy: admins] dangerous actions granted by \"container_*\" rather than by name: " +
+			"container_archive, container_attach, container_attach_websocket, container_copyfiles, " +
+			"container_exec_create, container_exec_start, container_export",
+		loc("2") + "[policy: ops] action pattern \"network_zz*\" matches no known action",
+		loc("3") + "[policy: rest] empty user applies the policy to every user not listed by an earlier policy",
+		loc("3") + "[policy: rest] dangerous actions granted to every user by \"container_attach\": container_attach",
+		loc("4") + "[policy: carol] policy is shadowed by earlier policies and never applies: \"carol\" by \"rest\", \"dave\" by \"rest\"",
+		loc("5") + "[policy: again] user \"alice\" already appears in policy \"admins\", only single policy applies",
+		loc("5") + "[policy: again] policy is shadowed by earlier policies and never applies: \"alice\" by \"admins\"",
+		loc("6") + "[policy: all] policy is shadowed by earlier policies and never applies: \"erin\" by \"rest\"",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected warnings\n%s", strings.Join(got, "\n"))
+	}
+}
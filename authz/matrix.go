@@ -0,0 +1,167 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: effective permissions of every user for every action
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"sort"
+)
+
+// AnyUser labels the users no policy lists by name in a permission matrix
+const AnyUser = "*"
+
+// PermissionMatrix is the effective permission of every user named in the
+// policies, and of any other user, for every action of the route table
+type PermissionMatrix struct {
+	Users   []string        `json:"users"`   // Users are the policy users in policy order, then AnyUser
+	Actions []MatrixAction  `json:"actions"` // Actions are the route table actions, sorted
+	Cells   [][]*MatrixCell `json:"cells"`   // Cells are indexed by user, then action
+}
+
+// MatrixAction is an action of the route table
+type MatrixAction struct {
+	Name  string `json:"name"`
+	Class string `json:"class"`
+}
+
+// MatrixCell is the permission of a user for an action
+type MatrixCell struct {
+	Allow      bool       `json:"allow"`
+	Policy     string     `json:"policy,omitempty"`     // Policy is the policy applying to the user
+	Rule       string     `json:"rule,omitempty"`       // Rule is the entry or class granting the action
+	Source     string     `json:"source,omitempty"`     // Source is where the policy was loaded from
+	Reason     ReasonCode `json:"reason"`               // Reason tells why the action is allowed or denied
+	Readonly   bool       `json:"readonly,omitempty"`   // Readonly is set if the policy is readonly
+	Conditions []string   `json:"conditions,omitempty"` // Conditions restrict when an allowed action is allowed
+}
+
+// NewPermissionMatrix loads the configured policies and expands them to
+// a permission matrix
+func NewPermissionMatrix(config *Config) (*PermissionMatrix, error) {
+	e, report := loadPolicies(config)
+	if err := report.Err(); err != nil {
+		return nil, err
+	}
+	return e.matrix(), nil
+}
+
+// Cell returns the permission of user for action, nil if either is not in
+// the matrix
+func (m *PermissionMatrix) Cell(user, action string) *MatrixCell {
+	u, a := -1, -1
+	for i := range m.Users {
+		if m.Users[i] == user {
+			u = i
+		}
+	}
+	for i := range m.Actions {
+		if m.Actions[i].Name == action {
+			a = i
+		}
+	}
+	if u < 0 || a < 0 {
+		return nil
+	}
+	return m.Cells[u][a]
+}
+
+func (e *engine) matrix() *PermissionMatrix {
+	m := &PermissionMatrix{}
+	seen := make(map[string]bool)
+	for _, p := range e.policies {
+		for _, u := range p.Users {
+			if u != "" && !seen[u] {
+				seen[u] = true
+				m.Users = append(m.Users, u)
+			}
+		}
+	}
+	m.Users = append(m.Users, AnyUser)
+
+	for action, class := range e.router.actions {
+		m.Actions = append(m.Actions, MatrixAction{Name: action, Class: class})
+	}
+	sort.Slice(m.Actions, func(i, j int) bool { return m.Actions[i].Name < m.Actions[j].Name })
+
+	for _, u := range m.Users {
+		user := u
+		if u == AnyUser {
+			// no policy lists the empty user by name, it finds the wildcard policy
+			user = ""
+		}
+		row := make([]*MatrixCell, 0, len(m.Actions))
+		for _, a := range m.Actions {
+			row = append(row, e.matrixCell(user, a.Name))
+		}
+		m.Cells = append(m.Cells, row)
+	}
+	return m
+}
+
+// matrixCell decides action for user and annotates the api versions it is
+// allowed for
+func (e *engine) matrixCell(user, action string) *MatrixCell {
+	d := e.decide(user, action)
+	cell := &MatrixCell{Allow: d.Allowed(), Policy: d.Policy, Rule: d.Rule, Source: d.Source, Reason: d.Reason}
+	policy := e.index.find(user)
+	if policy == nil {
+		return cell
+	}
+	cell.Readonly = policy.Readonly
+	if !cell.Allow {
+		return cell
+	}
+
+	routeMin, routeMax := e.router.actionVersions(action)
+	min := highestVersion(e.minAPIVersion, policy.MinAPIVersion, routeMin)
+	max := lowestVersion(e.maxAPIVersion, policy.MaxAPIVersion, routeMax)
+	switch {
+	case min != "" && max != "" && compareAPIVersions(min, max) > 0:
+		cell.Allow = false
+		cell.Reason = ReasonAPIVersion
+		return cell
+	case min != "" && max != "":
+		cell.Conditions = append(cell.Conditions, fmt.Sprintf("api %s-%s", min, max))
+	case min != "":
+		cell.Conditions = append(cell.Conditions, fmt.Sprintf("api >= %s", min))
+	case max != "":
+		cell.Conditions = append(cell.Conditions, fmt.Sprintf("api <= %s", max))
+	}
+	if e.unversionedAPI == UnversionedAPIDeny {
+		cell.Conditions = append(cell.Conditions, "versioned api only")
+	}
+	return cell
+}
+
+// highestVersion returns the highest of the non-empty versions
+func highestVersion(versions ...string) string {
+	highest := ""
+	for _, v := range versions {
+		if v != "" && (highest == "" || compareAPIVersions(v, highest) > 0) {
+			highest = v
+		}
+	}
+	return highest
+}
+
+// lowestVersion returns the lowest of the non-empty versions
+func lowestVersion(versions ...string) string {
+	lowest := ""
+	for _, v := range versions {
+		if v != "" && (lowest == "" || compareAPIVersions(v, lowest) < 0) {
+			lowest = v
+		}
+	}
+	return lowest
+}
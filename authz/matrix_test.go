@@ -0,0 +1,59 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: permission matrix tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPermissionMatrix(t *testing.T) {
+	p := writePolicyFile(t, strings.Join([]string{
+		`{"name":"admins","users":["alice"],"actions":["*"],"maxApiVersion":"1.40"}`,
+		`{"name":"viewers","users":["bob"],"actions":["container_*"],"readonly":true}`,
+		`{"name":"old","users":["carol"],"actions":["container_copyfiles"],"minApiVersion":"1.30"}`,
+		`{"name":"rest","users":[""],"actions":["image_list"]}`,
+	}, "\n")+"\n")
+	m, err := NewPermissionMatrix(&Config{PolicyPath: p, MinAPIVersion: "1.12"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Users, []string{"alice", "bob", "carol", AnyUser}) {
+		t.Fatalf("unexpected users %v", m.Users)
+	}
+	for _, c := range []struct {
+		user, action string
+		allow        bool
+		reason       ReasonCode
+		readonly     bool
+		conditions   []string
+	}{
+		{"alice", "container_start", true, ReasonGranted, false, []string{"api 1.12-1.40"}},
+		{"alice", "container_archive", true, ReasonGranted, false, []string{"api 1.20-1.40"}},
+		{"bob", "container_list", true, ReasonGranted, true, []string{"api >= 1.12"}},
+		{"bob", "container_start", false, ReasonReadonly, true, nil},
+		{"carol", "container_copyfiles", false, ReasonAPIVersion, false, nil},
+		{AnyUser, "image_list", true, ReasonGranted, false, []string{"api >= 1.12"}},
+		{AnyUser, "container_list", false, ReasonNotGranted, false, nil},
+	} {
+		cell := m.Cell(c.user, c.action)
+		if cell == nil {
+			t.Fatalf("no cell for %s %s", c.user, c.action)
+		}
+		if cell.Allow != c.allow || cell.Reason != c.reason || cell.Readonly != c.readonly || !reflect.DeepEqual(cell.Conditions, c.conditions) {
+			t.Errorf("%s %s: %+v", c.user, c.action, cell)
+		}
+	}
+}
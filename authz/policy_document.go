@@ -0,0 +1,251 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: single document policy format in yaml or json
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PolicyDocumentVersion is the supported version of the policy document format
+const PolicyDocumentVersion = 1
+
+// policy file formats
+const (
+	// FormatJSONLines is one json policy per line
+	FormatJSONLines = "jsonl"
+	// FormatYAML is a yaml policy document
+	FormatYAML = "yaml"
+	// FormatJSON is a json policy document
+	FormatJSON = "json"
+)
+
+// policyDocument is a versioned policy file with defaults for all policies
+type policyDocument struct {
+	Version  int              `yaml:"version" json:"version"`
+	Include  []string         `yaml:"include,omitempty" json:"include,omitempty"`
+	Defaults *policyDefaults  `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	Policies []documentPolicy `yaml:"policies" json:"policies"`
+}
+
+// policyDefaults apply to every policy of the document not setting them
+type policyDefaults struct {
+	Readonly      bool   `yaml:"readonly,omitempty" json:"readonly,omitempty"`
+	MinAPIVersion string `yaml:"minApiVersion,omitempty" json:"minApiVersion,omitempty"`
+	MaxAPIVersion string `yaml:"maxApiVersion,omitempty" json:"maxApiVersion,omitempty"`
+}
+
+// documentPolicy is a policy in a document, readonly is a pointer so an
+// explicit false overrides the defaults
+type documentPolicy struct {
+	Name          string   `yaml:"name" json:"name"`
+	Users         []string `yaml:"users" json:"users"`
+	Actions       []string `yaml:"actions,omitempty" json:"actions,omitempty"`
+	Classes       []string `yaml:"classes,omitempty" json:"classes,omitempty"`
+	Readonly      *bool    `yaml:"readonly,omitempty" json:"readonly,omitempty"`
+	MinAPIVersion string   `yaml:"minApiVersion,omitempty" json:"minApiVersion,omitempty"`
+	MaxAPIVersion string   `yaml:"maxApiVersion,omitempty" json:"maxApiVersion,omitempty"`
+}
+
+var (
+	yamlLineRegexp = regexp.MustCompile(`line (\d+): `)
+	yamlTypeRegexp = regexp.MustCompile(` in type [\w.]+$`)
+)
+
+// isPolicyDocument reports whether data is a policy document rather than
+// json lines. Yaml files and json objects with a version or policies key
+// are documents, a policy line has neither.
+func isPolicyDocument(policyPath string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(policyPath)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return false
+	}
+	if trimmed[0] != '{' {
+		return true
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &keys); err != nil {
+		return false
+	}
+	_, hasVersion := keys["version"]
+	_, hasPolicies := keys["policies"]
+	return hasVersion || hasPolicies
+}
+
+// parsePolicyDocument parses a yaml or json policy document and applies its
+// defaults to the policies, included files are loaded before them
+func (l *policyLoader) parsePolicyDocument(policyPath string, data []byte) {
+	var doc policyDocument
+	if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+		l.report.Errors = append(l.report.Errors, yamlErrors(policyPath, err)...)
+		return
+	}
+	if doc.Version != PolicyDocumentVersion {
+		l.report.Errors = append(l.report.Errors, &PolicyError{
+			File: policyPath,
+			Line: findKeyLine(data, "version", "", 0),
+			Err:  fmt.Errorf("unsupported policy document version %d, must be %d", doc.Version, PolicyDocumentVersion),
+		})
+		return
+	}
+	if len(doc.Include) > 0 {
+		pos := position{file: policyPath, line: findKeyLine(data, "include", "", 0)}
+		for _, pattern := range doc.Include {
+			l.include(pattern, pos)
+		}
+	}
+	defaults := policyDefaults{}
+	if doc.Defaults != nil {
+		defaults = *doc.Defaults
+	}
+
+	line := 0
+	for _, dp := range doc.Policies {
+		if dp.Name != "" {
+			line = findKeyLine(data, "name", dp.Name, line)
+		}
+		policy := Policy{
+			Name:          dp.Name,
+			Users:         dp.Users,
+			Actions:       dp.Actions,
+			Classes:       dp.Classes,
+			Readonly:      defaults.Readonly,
+			MinAPIVersion: defaults.MinAPIVersion,
+			MaxAPIVersion: defaults.MaxAPIVersion,
+			pos:           position{file: policyPath, line: line},
+		}
+		if dp.Readonly != nil {
+			policy.Readonly = *dp.Readonly
+		}
+		if dp.MinAPIVersion != "" {
+			policy.MinAPIVersion = dp.MinAPIVersion
+		}
+		if dp.MaxAPIVersion != "" {
+			policy.MaxAPIVersion = dp.MaxAPIVersion
+		}
+		l.policies = append(l.policies, policy)
+	}
+}
+
+// yamlErrors converts a yaml decoding error to positioned errors, one for
+// every field that failed to decode
+func yamlErrors(policyPath string, err error) []*PolicyError {
+	msgs := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	if te, ok := err.(*yaml.TypeError); ok {
+		msgs = te.Errors
+	}
+	var errs []*PolicyError
+	for _, msg := range msgs {
+		msg = yamlTypeRegexp.ReplaceAllString(msg, "")
+		pe := &PolicyError{File: policyPath, Err: fmt.Errorf("%s", msg)}
+		if m := yamlLineRegexp.FindStringSubmatchIndex(msg); m != nil {
+			pe.Line, _ = strconv.Atoi(msg[m[2]:m[3]])
+			pe.Err = fmt.Errorf("%s", msg[m[1]:])
+		}
+		errs = append(errs, pe)
+	}
+	return errs
+}
+
+// findKeyLine returns the 1-based line after line from on which key is set
+// to value, or from if there is none. yaml.v2 does not report node
+// positions, so policies are located by their unique names.
+func findKeyLine(data []byte, key, value string, from int) int {
+	keyRegexp := regexp.MustCompile(`(^|[\s{,"'-])` + regexp.QuoteMeta(key) + `["']?\s*:(.*)$`)
+	lines := strings.Split(string(data), "\n")
+	for i := from; i < len(lines); i++ {
+		m := keyRegexp.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		if value == "" {
+			return i + 1
+		}
+		v := m[2]
+		if c := strings.Index(v, " #"); c >= 0 {
+			v = v[:c]
+		}
+		if strings.Trim(v, ` "',}`) == value || strings.Contains(v, strconv.Quote(value)) {
+			return i + 1
+		}
+	}
+	return from
+}
+
+// EncodePolicies encodes policies in a policy file format
+func EncodePolicies(policies []Policy, format string) ([]byte, error) {
+	if format == FormatJSONLines {
+		var buf bytes.Buffer
+		for i := range policies {
+			data, err := json.Marshal(&policies[i])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	}
+
+	doc := policyDocument{Version: PolicyDocumentVersion, Policies: make([]documentPolicy, 0, len(policies))}
+	for _, p := range policies {
+		dp := documentPolicy{
+			Name:          p.Name,
+			Users:         p.Users,
+			Actions:       p.Actions,
+			Classes:       p.Classes,
+			MinAPIVersion: p.MinAPIVersion,
+			MaxAPIVersion: p.MaxAPIVersion,
+		}
+		if p.Readonly {
+			readonly := true
+			dp.Readonly = &readonly
+		}
+		doc.Policies = append(doc.Policies, dp)
+	}
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(&doc)
+	case FormatJSON:
+		data, err := json.MarshalIndent(&doc, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	return nil, fmt.Errorf("unknown policy format %q, must be one of %s, %s or %s", format, FormatJSONLines, FormatYAML, FormatJSON)
+}
+
+// ConvertPolicyFile parses a policy file in any supported format and encodes
+// its policies in format. Defaults of documents are applied to each policy
+// and included files are inlined.
+func ConvertPolicyFile(policyPath, format string) ([]byte, error) {
+	report := &ValidationReport{}
+	policies := parsePolicy(policyPath, report)
+	if err := report.Err(); err != nil {
+		return nil, err
+	}
+	return EncodePolicies(policies, format)
+}
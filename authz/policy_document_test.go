@@ -0,0 +1,129 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy document format tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const yamlPolicyDocument = `# cluster policy
+version: 1
+defaults:
+  readonly: true
+  minApiVersion: "1.24"
+policies:
+  # admins may do anything
+  - name: admins
+    users: [alice]
+    actions: ["*"]
+    readonly: false
+  - users:
+      - bob
+    name: viewers
+    classes: [read]
+    maxApiVersion: "1.40"
+`
+
+const jsonPolicyDocument = `{
+  "version": 1,
+  "defaults": {"readonly": true, "minApiVersion": "1.24"},
+  "policies": [
+    {"name": "admins", "users": ["alice"], "actions": ["*"], "readonly": false},
+    {
+      "users": ["bob"],
+      "name": "viewers",
+      "classes": ["read"],
+      "maxApiVersion": "1.40"
+    }
+  ]
+}
+`
+
+func TestParsePolicyDocument(t *testing.T) {
+	expected := []Policy{
+		{Name: "admins", Users: []string{"alice"}, Actions: []string{"*"}, MinAPIVersion: "1.24"},
+		{Name: "viewers", Users: []string{"bob"}, Classes: []string{"read"}, Readonly: true, MinAPIVersion: "1.24", MaxAPIVersion: "1.40"},
+	}
+	for _, c := range []struct {
+		doc   string
+		lines []int
+	}{
+		{yamlPolicyDocument, []int{8, 14}},
+		{jsonPolicyDocument, []int{5, 8}},
+	} {
+		p := writePolicyFile(t, c.doc)
+		report := &ValidationReport{}
+		policies := parsePolicy(p, report)
+		if err := report.Err(); err != nil {
+			t.Fatalf("failed to parse document: %v", err)
+		}
+		for i := range policies {
+			if policies[i].pos.line != c.lines[i] {
+				t.Errorf("policy %s at line %d, expected %d", policies[i].Name, policies[i].pos.line, c.lines[i])
+			}
+			policies[i].pos = position{}
+		}
+		if !reflect.DeepEqual(policies, expected) {
+			t.Errorf("parsed %+v, expected %+v", policies, expected)
+		}
+	}
+}
+
+func TestParsePolicyDocumentErrors(t *testing.T) {
+	for _, c := range []struct {
+		doc string
+		err string
+	}{
+		{"version: 2\npolicies: []\n", ":1: unsupported policy document version 2"},
+		{"policies: []\n", "unsupported policy document version 0"},
+		{"version: 1\npolicies:\n  - name: a\n    acions: [x]\n", ":4: field acions not found"},
+		{"version: 1\npolicies:\n  - name: a\n    users: 3\n", ":4: cannot unmarshal !!int `3` into []string"},
+		{`{"version": 1, "policies": [{"name": "a", "user": ["x"]}]}`, ":1: field user not found"},
+	} {
+		p := writePolicyFile(t, c.doc)
+		report := &ValidationReport{}
+		parsePolicy(p, report)
+		if err := report.Err(); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q: error %v, expected %q", c.doc, err, c.err)
+		}
+	}
+}
+
+func TestConvertPolicyFile(t *testing.T) {
+	p := writePolicyFile(t, yamlPolicyDocument)
+	report := &ValidationReport{}
+	expected := parsePolicy(p, report)
+	for _, format := range []string{FormatJSONLines, FormatJSON, FormatYAML} {
+		data, err := ConvertPolicyFile(p, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := ioutil.WriteFile(p, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		policies := parsePolicy(p, report)
+		if err := report.Err(); err != nil {
+			t.Fatalf("%s: failed to parse converted policies: %v\n%s", format, err, data)
+		}
+		for i := range policies {
+			policies[i].pos, expected[i].pos = position{}, position{}
+		}
+		if !reflect.DeepEqual(policies, expected) {
+			t.Errorf("%s: converted %+v, expected %+v", format, policies, expected)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy file parsing with positioned errors
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// position is the location of a policy in its file
+type position struct {
+	file string
+	line int // line is 1-based, 0 if unknown
+}
+
+// String formats the position as file:line
+func (p position) String() string {
+	if p.line == 0 {
+		return p.file
+	}
+	return fmt.Sprintf("%s:%d", p.file, p.line)
+}
+
+// PolicyError is an error at a position in a policy file
+type PolicyError struct {
+	File   string // File is the policy file
+	Line   int    // Line is 1-based, 0 if unknown
+	Column int    // Column is 1-based, 0 if unknown
+	Policy string // Policy is the name of the offending policy, if known
+	Err    error  // Err is the underlying error
+}
+
+// Location formats the position of the error as file:line:column, it is
+// empty if the file is unknown
+func (e *PolicyError) Location() string {
+	loc := e.File
+	if loc != "" && e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, e.Line)
+		if e.Column > 0 {
+			loc = fmt.Sprintf("%s:%d", loc, e.Column)
+		}
+	}
+	return loc
+}
+
+// Message formats the error without its position
+func (e *PolicyError) Message() string {
+	if e.Policy != "" {
+		return fmt.Sprintf("[policy: %s] %v", e.Policy, e.Err)
+	}
+	return e.Err.Error()
+}
+
+// Error formats the error as file:line:column: message
+func (e *PolicyError) Error() string {
+	if loc := e.Location(); loc != "" {
+		return loc + ": " + e.Message()
+	}
+	return e.Message()
+}
+
+// MarshalJSON encodes the error with its message for machine readable reports
+func (e *PolicyError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		File    string `json:"file,omitempty"`
+		Line    int    `json:"line,omitempty"`
+		Column  int    `json:"column,omitempty"`
+		Policy  string `json:"policy,omitempty"`
+		Message string `json:"message"`
+	}{e.File, e.Line, e.Column, e.Policy, e.Err.Error()})
+}
+
+// policyErrorAt creates a PolicyError for the policy at pos
+func policyErrorAt(pos position, name string, format string, args ...interface{}) *PolicyError {
+	return &PolicyError{File: pos.file, Line: pos.line, Policy: name, Err: fmt.Errorf(format, args...)}
+}
+
+// jsonErrorColumn returns the 1-based column in line a json decoding error
+// points at
+func jsonErrorColumn(line string, err error) int {
+	if err == io.ErrUnexpectedEOF {
+		return len(line)
+	}
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		return int(e.Offset)
+	case *json.UnmarshalTypeError:
+		return int(e.Offset)
+	}
+	if field := strings.TrimPrefix(err.Error(), "json: unknown field "); field != err.Error() {
+		return strings.Index(line, field) + 1
+	}
+	return 0
+}
+
+// parsePolicyLines parses a file with one json policy per line. Malformed
+// lines are added to the report rather than skipped, so a typo is never
+// loaded. A line with only an include key includes other files there.
+func (l *policyLoader) parsePolicyLines(policyPath string, data []byte) {
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		pos := position{file: policyPath, line: i + 1}
+		if include, ok := decodeIncludeLine(line); ok {
+			for _, pattern := range include {
+				l.include(pattern, pos)
+			}
+			continue
+		}
+		policy := Policy{pos: pos}
+		if err := decodePolicyLine(line, &policy); err != nil {
+			l.report.Errors = append(l.report.Errors, &PolicyError{File: policyPath, Line: i + 1, Column: jsonErrorColumn(line, err), Err: err})
+			continue
+		}
+		l.policies = append(l.policies, policy)
+	}
+}
+
+// decodeIncludeLine decodes a line of the form {"include": [...]}
+func decodeIncludeLine(line string) ([]string, bool) {
+	if !strings.Contains(line, `"include"`) {
+		return nil, false
+	}
+	var directive struct {
+		Include []string `json:"include"`
+	}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&directive); err != nil || directive.Include == nil || dec.More() {
+		return nil, false
+	}
+	return directive.Include, true
+}
+
+// decodePolicyLine decodes a single json policy rejecting unknown fields and
+// trailing data
+func decodePolicyLine(line string, policy *Policy) error {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(policy); err != nil {
+		return err
+	}
+	if dec.More() {
+		return &json.SyntaxError{Offset: dec.InputOffset() + 1}
+	}
+	return nil
+}
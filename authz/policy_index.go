@@ -0,0 +1,130 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policies compiled once at load into an index keyed by user
+//              and action
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type compiledEntry struct {
+	entry string
+	name  string         // name is the action of exact entries
+	re    *regexp.Regexp // re is the compiled glob or regex, nil for exact entries
+}
+
+// compiledPolicy is a policy with its action entries compiled and matched
+// against every action of the route table in advance
+type compiledPolicy struct {
+	*Policy
+	entries []compiledEntry   // entries are the action entries in policy order
+	granted map[string]string // granted maps route table actions to the entry granting them
+	classes map[string]bool
+	invalid []error // invalid are the errors of entries failing to compile
+}
+
+func compilePolicy(policy *Policy, rt *router, legacy bool) *compiledPolicy {
+	cp := &compiledPolicy{
+		Policy:  policy,
+		granted: make(map[string]string),
+		classes: make(map[string]bool),
+	}
+	for _, entry := range policy.Actions {
+		re, err := compileActionEntry(entry, legacy)
+		if err != nil {
+			cp.invalid = append(cp.invalid, fmt.Errorf("failed to compile action entry %q: %v", entry, err))
+			continue
+		}
+		_, name := parseActionEntry(entry, legacy)
+		cp.entries = append(cp.entries, compiledEntry{entry: entry, name: name, re: re})
+	}
+	for _, c := range policy.Classes {
+		cp.classes[c] = true
+	}
+	for action := range rt.actions {
+		if rule := cp.matchEntries(action); rule != "" {
+			cp.granted[action] = rule
+		}
+	}
+	return cp
+}
+
+// matchEntries returns the first action entry matching action, in policy
+// order like the entries were evaluated one by one
+func (cp *compiledPolicy) matchEntries(action string) string {
+	for _, e := range cp.entries {
+		if (e.re == nil && e.name == action) || (e.re != nil && e.re.MatchString(action)) {
+			return e.entry
+		}
+	}
+	return ""
+}
+
+// match returns the action entry or class granting the action, or empty if
+// none does
+func (cp *compiledPolicy) match(action, class string, known bool) (string, error) {
+	rule, ok := cp.granted[action]
+	if !ok && !known {
+		rule = cp.matchEntries(action)
+	}
+	if rule != "" {
+		return rule, nil
+	}
+	if class != "" && cp.classes[class] {
+		return classRulePrefix + class, nil
+	}
+	if len(cp.invalid) > 0 {
+		return "", cp.invalid[0]
+	}
+	return "", nil
+}
+
+// policyIndex finds the policy applying to a user without scanning policies
+type policyIndex struct {
+	policies []*compiledPolicy
+	users    map[string]int // users maps users to the first policy listing them
+	wildcard int            // wildcard is the first policy listing the empty user, -1 if none
+}
+
+func newPolicyIndex(policies []Policy, rt *router, legacy bool) *policyIndex {
+	idx := &policyIndex{users: make(map[string]int), wildcard: -1}
+	for i := range policies {
+		idx.policies = append(idx.policies, compilePolicy(&policies[i], rt, legacy))
+		for _, u := range policies[i].Users {
+			if u == "" {
+				if idx.wildcard < 0 {
+					idx.wildcard = i
+				}
+				continue
+			}
+			if _, ok := idx.users[u]; !ok {
+				idx.users[u] = i
+			}
+		}
+	}
+	return idx
+}
+
+// find returns the first policy listing user or the empty user
+func (idx *policyIndex) find(user string) *compiledPolicy {
+	i, ok := idx.users[user]
+	if !ok || (idx.wildcard >= 0 && idx.wildcard < i) {
+		i = idx.wildcard
+	}
+	if i < 0 {
+		return nil
+	}
+	return idx.policies[i]
+}
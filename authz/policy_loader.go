@@ -0,0 +1,148 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: loading policy files, drop-in directories and includes
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// policyExtensions are the extensions of files loaded from policy directories
+var policyExtensions = map[string]bool{".json": true, ".jsonl": true, ".yaml": true, ".yml": true}
+
+// policyLoader loads policy files in order, following includes. Every file
+// is loaded at most once, so a drop-in file also included explicitly is not
+// loaded twice.
+type policyLoader struct {
+	report   *ValidationReport
+	hash     hash.Hash
+	loaded   map[string]bool // loaded are the absolute paths of files loaded or being loaded
+	loading  map[string]bool // loading are the absolute paths of files being loaded, for cycles
+	policies []Policy
+}
+
+func newPolicyLoader(report *ValidationReport) *policyLoader {
+	return &policyLoader{
+		report:  report,
+		hash:    sha256.New(),
+		loaded:  make(map[string]bool),
+		loading: make(map[string]bool),
+	}
+}
+
+// parsePolicy loads a policy file and the files it includes
+func parsePolicy(policyPath string, report *ValidationReport) []Policy {
+	l := newPolicyLoader(report)
+	l.loadFile(policyPath)
+	return l.policies
+}
+
+// read reads a file and adds it to the files and hash of the report
+func (l *policyLoader) read(p string) ([]byte, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	l.report.Files = append(l.report.Files, p)
+	l.hash.Write([]byte(p))
+	l.hash.Write([]byte{0})
+	l.hash.Write(data)
+	return data, nil
+}
+
+// sum returns the hash of all files read so far
+func (l *policyLoader) sum() string {
+	return hex.EncodeToString(l.hash.Sum(nil))
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return filepath.Clean(p)
+}
+
+// loadFile loads the policies of a file, included files are loaded where
+// the include appears
+func (l *policyLoader) loadFile(p string) {
+	key := absPath(p)
+	if l.loaded[key] {
+		return
+	}
+	l.loaded[key] = true
+	l.loading[key] = true
+	defer delete(l.loading, key)
+
+	data, err := l.read(p)
+	if err != nil {
+		l.report.Errors = append(l.report.Errors, &PolicyError{Err: err})
+		return
+	}
+	if isPolicyDocument(p, data) {
+		l.parsePolicyDocument(p, data)
+		return
+	}
+	l.parsePolicyLines(p, data)
+}
+
+// loadDir loads the policy files of a directory in lexical order, hidden
+// files and files of other extensions are skipped
+func (l *policyLoader) loadDir(dir string) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		l.report.Errors = append(l.report.Errors, &PolicyError{Err: err})
+		return
+	}
+	for _, fi := range entries {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || !policyExtensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		l.loadFile(filepath.Join(dir, name))
+	}
+}
+
+// include loads the files matching pattern, relative to the directory of
+// the including file. An included directory is loaded like a drop-in
+// directory, a pattern without wildcards must match a file.
+func (l *policyLoader) include(pattern string, pos position) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(pos.file), pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		l.report.errorf(pos, "", "invalid include %q: %v", pattern, err)
+		return
+	}
+	if len(matches) == 0 && !strings.ContainsAny(pattern, `*?[`) {
+		l.report.errorf(pos, "", "included file %s does not exist", pattern)
+		return
+	}
+	for _, m := range matches {
+		if l.loading[absPath(m)] {
+			l.report.errorf(pos, "", "include cycle through %s", m)
+			continue
+		}
+		if fi, err := os.Stat(m); err == nil && fi.IsDir() {
+			l.loadDir(m)
+			continue
+		}
+		l.loadFile(m)
+	}
+}
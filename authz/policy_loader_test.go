@@ -0,0 +1,138 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy drop-in directory and include tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePolicyTree writes files relative to a new temporary directory
+func writePolicyTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "authz-loader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPolicyDirMergeOrder(t *testing.T) {
+	dir := writePolicyTree(t, map[string]string{
+		"policy.json": `{"name":"main","users":["alice"],"actions":["container_list"]}` + "\n",
+		"policy.d/20-b.json": `{"name":"b","users":["alice","bob"],"actions":["*"]}` + "\n" +
+			`{"include":["../shared/*.yaml"]}` + "\n",
+		"policy.d/10-a.yaml":    "version: 1\npolicies:\n  - name: a\n    users: [bob, carol]\n    classes: [read]\n",
+		"policy.d/README":       "not a policy",
+		"policy.d/.hidden.json": "{",
+		"shared/ops.yaml":       "version: 1\npolicies:\n  - name: ops\n    users: [dave]\n    actions: [\"*\"]\n",
+	})
+	config := &Config{PolicyPath: filepath.Join(dir, "policy.json"), PolicyDir: filepath.Join(dir, "policy.d")}
+	e, report := loadPolicies(config)
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range e.policies {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "main,a,b,ops" {
+		t.Errorf("policies loaded in order %v", names)
+	}
+
+	for _, c := range []struct {
+		user, policy, source string
+	}{
+		{"alice", "main", "policy.json:1"},
+		{"bob", "a", "policy.d/10-a.yaml:3"},
+		{"dave", "ops", "shared/ops.yaml:3"},
+	} {
+		d := e.decide(c.user, "container_list")
+		if d.Policy != c.policy || d.Source != filepath.Join(dir, c.source) {
+			t.Errorf("%s: decided by %s from %s, expected %s from %s", c.user, d.Policy, d.Source, c.policy, c.source)
+		}
+	}
+}
+
+func TestPolicyDirErrors(t *testing.T) {
+	for _, c := range []struct {
+		files map[string]string
+		err   string
+	}{
+		{
+			map[string]string{
+				"policy.d/a.json": `{"name":"dup","users":["alice"],"actions":["*"]}` + "\n",
+				"policy.d/b.json": `{"name":"other","users":["bob"],"actions":["*"]}` + "\n" + `{"name":"dup","users":["bob"],"actions":["*"]}` + "\n",
+			},
+			"policy.d/b.json:2: [policy: dup] duplicate policy name, first defined at ",
+		},
+		{
+			map[string]string{
+				"policy.d/a.json": `{"include":["../x.json"]}` + "\n",
+				"x.json":          `{"include":["policy.d/a.json"]}` + "\n",
+			},
+			"x.json:1: include cycle through ",
+		},
+		{
+			map[string]string{"policy.d/a.json": `{"include":["missing.json"]}` + "\n"},
+			"policy.d/a.json:1: included file ",
+		},
+	} {
+		dir := writePolicyTree(t, c.files)
+		_, report := loadPolicies(&Config{PolicyDir: filepath.Join(dir, "policy.d")})
+		if err := report.Err(); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("error %v, expected %q", err, c.err)
+		}
+	}
+}
+
+func TestPolicyDirReload(t *testing.T) {
+	dir := writePolicyTree(t, map[string]string{
+		"policy.d/10-a.json": `{"name":"a","users":["alice"],"actions":["container_list"]}` + "\n",
+	})
+	f := NewAuthorizer(&Config{PolicyDir: filepath.Join(dir, "policy.d")}).(*authorizer)
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "policy.d/20-b.json"), []byte(`{"name":"b","users":["bob"],"actions":["*"]}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.reload(false); err != nil {
+		t.Fatal(err)
+	}
+	if d := f.Decide("bob", "container_list"); d.Policy != "b" {
+		t.Fatalf("added drop-in file not loaded: %+v", d)
+	}
+	if err := os.Remove(filepath.Join(dir, "policy.d/20-b.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.reload(false); err != nil {
+		t.Fatal(err)
+	}
+	if d := f.Decide("bob", "container_list"); d.Policy != "" {
+		t.Fatalf("removed drop-in file still loaded: %+v", d)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: replay audited requests against candidate policies
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+// AuditRecord is a request decision read back from the audit log
+type AuditRecord struct {
+	Time    string `json:"time,omitempty"`
+	User    string `json:"user"`
+	Method  string `json:"method"`
+	URI     string `json:"uri"`
+	Action  string `json:"action,omitempty"`
+	Allow   bool   `json:"allow"`
+	Message string `json:"message,omitempty"` // Message is the audited response message
+	Source  string `json:"source,omitempty"`  // Source is the file and line the record was read from
+}
+
+// auditLine is an audit log line, logrus renames the msg field of the
+// response message as it clashes with the log message
+type auditLine struct {
+	AuditRecord
+	Msg string `json:"fields.msg"`
+}
+
+// ReadAuditLog reads the audit records of r, name is used in their source.
+// Records are the json lines the auditor logs, also after a syslog prefix
+// such as "Oct 19 10:00:00 host authz[42]: ". Lines that are not request
+// records are skipped and counted.
+func ReadAuditLog(r io.Reader, name string) ([]*AuditRecord, int, error) {
+	var records []*AuditRecord
+	skipped := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		i := strings.Index(text, "{")
+		if i < 0 {
+			if strings.TrimSpace(text) != "" {
+				skipped++
+			}
+			continue
+		}
+		var l auditLine
+		if err := json.Unmarshal([]byte(text[i:]), &l); err != nil || l.Method == "" || l.URI == "" {
+			skipped++
+			continue
+		}
+		record := l.AuditRecord
+		record.Message = l.Msg
+		record.Source = fmt.Sprintf("%s:%d", name, line)
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, skipped, fmt.Errorf("failed to read audit log %s: %v", name, err)
+	}
+	return records, skipped, nil
+}
+
+// FlippedDecision is an audited request decided the other way by the
+// candidate policies
+type FlippedDecision struct {
+	Record   *AuditRecord `json:"record"`
+	Kind     ChangeKind   `json:"kind"`
+	Decision *Decision    `json:"decision"`
+}
+
+// FlipCount counts the flipped decisions of a user and action
+type FlipCount struct {
+	User   string     `json:"user"`
+	Action string     `json:"action"`
+	Kind   ChangeKind `json:"kind"`
+	Count  int        `json:"count"`
+}
+
+// ReplayReport is the outcome of replaying audit records
+type ReplayReport struct {
+	Records int                `json:"records"` // Records is the number of records replayed
+	Flips   []*FlippedDecision `json:"flips"`   // Flips are the flipped decisions in record order
+	Counts  []*FlipCount       `json:"counts"`  // Counts are sorted by user, action and kind
+}
+
+// Replay decides the audited requests with authorizer and reports the
+// decisions differing from the audited ones. Request bodies and headers are
+// not audited, so rules depending on them are decided without.
+func Replay(authorizer Authorizer, records []*AuditRecord) *ReplayReport {
+	report := &ReplayReport{Records: len(records)}
+	counts := make(map[FlipCount]int)
+	for _, r := range records {
+		d := authorizer.DecideRequest(&authorization.Request{
+			User:          r.User,
+			RequestMethod: r.Method,
+			RequestURI:    r.URI,
+		})
+		if d.Allowed() == r.Allow {
+			continue
+		}
+		kind := ChangeGain
+		if r.Allow {
+			kind = ChangeLoss
+		}
+		report.Flips = append(report.Flips, &FlippedDecision{Record: r, Kind: kind, Decision: d})
+		counts[FlipCount{User: r.User, Action: d.Action, Kind: kind}]++
+	}
+
+	for key, n := range counts {
+		c := key
+		c.Count = n
+		report.Counts = append(report.Counts, &c)
+	}
+	sort.Slice(report.Counts, func(i, j int) bool {
+		a, b := report.Counts[i], report.Counts[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		if a.Action != b.Action {
+			return a.Action < b.Action
+		}
+		return a.Kind < b.Kind
+	})
+	return report
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: audit log replay tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testAuditLog = `Oct 19 10:00:00 host authz[42]: {"action":"container_list","allow":true,"fields.msg":"allowed","level":"info","method":"GET","msg":"Request","uri":"/v1.40/containers/json","user":"bob"}
+{"action":"container_exec_create","allow":true,"level":"info","method":"POST","msg":"Request","uri":"/v1.40/containers/abc/exec","user":"bob"}
+{"action":"container_exec_create","allow":true,"level":"info","method":"POST","msg":"Request","uri":"/v1.40/containers/abd/exec","user":"bob"}
+time="2026-10-19T10:00:03Z" level=info msg="Loaded policies"
+{"level":"info","msg":"Policy reload"}
+
+{"action":"image_list","allow":false,"level":"info","method":"GET","msg":"Request","uri":"/v1.40/images/json","user":"carol"}
+`
+
+func TestReadAuditLog(t *testing.T) {
+	records, skipped, err := ReadAuditLog(strings.NewReader(testAuditLog), "audit.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || skipped != 2 {
+		t.Fatalf("read %d records and skipped %d lines", len(records), skipped)
+	}
+	want := &AuditRecord{User: "bob", Method: "GET", URI: "/v1.40/containers/json", Action: "container_list",
+		Allow: true, Message: "allowed", Source: "audit.log:1"}
+	if !reflect.DeepEqual(records[0], want) {
+		t.Errorf("unexpected record %+v", records[0])
+	}
+	if records[3].Source != "audit.log:7" {
+		t.Errorf("unexpected source %s", records[3].Source)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	p := writePolicyFile(t, strings.Join([]string{
+		`{"name":"viewers","users":["bob"],"classes":["read"]}`,
+		`{"name":"rest","users":[""],"actions":["image_list"]}`,
+	}, "\n")+"\n")
+	authorizer := NewAuthorizer(&Config{PolicyPath: p})
+	if err := authorizer.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	records, _, err := ReadAuditLog(strings.NewReader(testAuditLog), "audit.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Replay(authorizer, records)
+	if report.Records != 4 || len(report.Flips) != 3 {
+		t.Fatalf("%d flips of %d records", len(report.Flips), report.Records)
+	}
+	if f := report.Flips[2]; f.Record.User != "carol" || f.Kind != ChangeGain || f.Decision.Policy != "rest" {
+		t.Errorf("unexpected flip %+v", f)
+	}
+	want := []*FlipCount{
+		{User: "bob", Action: "container_exec_create", Kind: ChangeLoss, Count: 2},
+		{User: "carol", Action: "image_list", Kind: ChangeGain, Count: 1},
+	}
+	if !reflect.DeepEqual(report.Counts, want) {
+		t.Errorf("unexpected counts %+v %+v", report.Counts[0], report.Counts[1])
+	}
+}
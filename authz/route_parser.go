@@ -15,91 +15,197 @@
 package authz
 
 import (
+	"fmt"
+	"net/url"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
+// UnknownAction is the action of a request that matches no known route
+const UnknownAction = "unknown"
+
+// route classes
+const (
+	// ClassRead marks routes that only read daemon or container state
+	ClassRead = "read"
+	// ClassWrite marks routes that change daemon or container state
+	ClassWrite = "write"
+	// ClassExec marks routes that run processes in or attach to containers
+	ClassExec = "exec"
+	// ClassAdmin marks routes that change daemon wide settings or credentials
+	ClassAdmin = "admin"
+	// ClassSensitiveRead marks routes that read whole filesystems or image contents
+	ClassSensitiveRead = "sensitive-read"
+)
+
+var routeClasses = []string{ClassRead, ClassWrite, ClassExec, ClassAdmin, ClassSensitiveRead}
+
+func validClass(class string) bool {
+	for _, c := range routeClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// apiVersionPrefixRegexp matches the first uri segments the daemon routes
+	// as api version prefix, as its /v{version:[0-9.]+} matcher does
+	apiVersionPrefixRegexp = regexp.MustCompile(`^v[0-9.]+$`)
+	// apiVersionRegexp matches well formed api versions
+	apiVersionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)*$`)
+)
+
+// ParseAPIVersion validates an api version of the form "1.40"
+func ParseAPIVersion(version string) error {
+	if !apiVersionRegexp.MatchString(version) {
+		return fmt.Errorf("invalid api version %q", version)
+	}
+	return nil
+}
+
+// compareAPIVersions compares two valid api versions numerically and returns
+// -1, 0 or 1 like strings.Compare
+func compareAPIVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// apiVersionInRange reports whether version is within [min, max], empty
+// bounds are open
+func apiVersionInRange(version, min, max string) bool {
+	if min != "" && compareAPIVersions(version, min) < 0 {
+		return false
+	}
+	return max == "" || compareAPIVersions(version, max) <= 0
+}
+
 type routeslice []route
 
+// route maps a method and path template to an isulad action. Path templates
+// consist of literal segments, "{param}" segments matching exactly one
+// segment and "{param*}" segments matching one or more segments. Routes with
+// minVersion or maxVersion only match requests within that api version range.
+// Routes without class are read routes for GET and HEAD and write routes
+// otherwise.
 type route struct {
-	pattern string
-	method  string
-	action  string
+	pattern    string
+	method     string
+	action     string
+	class      string
+	minVersion string
+	maxVersion string
+}
+
+func (r *route) routeClass() string {
+	if r.class != "" {
+		return r.class
+	}
+	if r.method == "GET" || r.method == "HEAD" {
+		return ClassRead
+	}
+	return ClassWrite
+}
+
+// RouteMatch is the result of resolving a request to an isulad action
+type RouteMatch struct {
+	Action     string // Action is the isulad action, UnknownAction if no route matches
+	Class      string // Class is the route class, empty if no route matches
+	APIVersion string // APIVersion is the api version prefix of the uri, empty if unversioned, malformed ones match no route
+	Resource   string // Resource is the first path parameter, e.g. the container name, empty if none
 }
 
 // isulad routes
 var isuladRoutes = []route{
-	{pattern: "/events", method: "GET", action: "isulad_events"},
-	{pattern: "/version", method: "GET", action: "isulad_version"},
-	{pattern: "/auth", method: "POST", action: "isulad_auth"},
-	{pattern: "/_ping", method: "GET", action: "isulad_ping"},
-	{pattern: "/info", method: "GET", action: "isulad_info"},
+	{pattern: "/events", method: "GET", action: "isulad_events", class: ClassRead},
+	{pattern: "/version", method: "GET", action: "isulad_version", class: ClassRead},
+	{pattern: "/auth", method: "POST", action: "isulad_auth", class: ClassAdmin},
+	{pattern: "/_ping", method: "GET", action: "isulad_ping", class: ClassRead},
+	{pattern: "/info", method: "GET", action: "isulad_info", class: ClassRead},
 }
 
 // image routes
 var imageRoutes = []route{
-	{pattern: "/build", method: "POST", action: "image_build"},
-	{pattern: "/images/.+/get", method: "GET", action: "images_archive"},
-	{pattern: "/images/search", method: "GET", action: "images_search"},
-	{pattern: "/images/.+/tag", method: "POST", action: "image_tag"},
-	{pattern: "/images/.+/json", method: "GET", action: "image_inspect"},
-	{pattern: "/images/.+", method: "DELETE", action: "image_delete"},
-	{pattern: "/images/.+/history", method: "GET", action: "image_history"},
-	{pattern: "/images/.+/push", method: "POST", action: "image_push"},
-	{pattern: "/images/create", method: "POST", action: "image_create"},
-	{pattern: "/images/load", method: "POST", action: "images_load"},
-	{pattern: "/images/json", method: "GET", action: "image_list"},
+	{pattern: "/build", method: "POST", action: "image_build", class: ClassWrite},
+	{pattern: "/images/{name*}/get", method: "GET", action: "images_archive", class: ClassSensitiveRead},
+	{pattern: "/images/search", method: "GET", action: "images_search", class: ClassRead},
+	{pattern: "/images/{name*}/tag", method: "POST", action: "image_tag", class: ClassWrite},
+	{pattern: "/images/{name*}/json", method: "GET", action: "image_inspect", class: ClassRead},
+	{pattern: "/images/{name*}", method: "DELETE", action: "image_delete", class: ClassWrite},
+	{pattern: "/images/{name*}/history", method: "GET", action: "image_history", class: ClassRead},
+	{pattern: "/images/{name*}/push", method: "POST", action: "image_push", class: ClassWrite},
+	{pattern: "/images/create", method: "POST", action: "image_create", class: ClassWrite},
+	{pattern: "/images/load", method: "POST", action: "images_load", class: ClassWrite},
+	{pattern: "/images/json", method: "GET", action: "image_list", class: ClassRead},
 }
 
 // volume routes
 var volumeRoutes = []route{
-	{pattern: "/volumes/.+", method: "GET", action: "volume_inspect"},
-	{pattern: "/volumes", method: "GET", action: "volume_list"},
-	{pattern: "/volumes/create", method: "POST", action: "volume_create"},
-	{pattern: "/volumes/.+", method: "DELETE", action: "volume_remove"},
+	{pattern: "/volumes/{name}", method: "GET", action: "volume_inspect", class: ClassRead},
+	{pattern: "/volumes", method: "GET", action: "volume_list", class: ClassRead},
+	{pattern: "/volumes/create", method: "POST", action: "volume_create", class: ClassWrite},
+	{pattern: "/volumes/{name}", method: "DELETE", action: "volume_remove", class: ClassWrite},
 }
 
 // nework routes
 var networkRoutes = []route{
-	{pattern: "/networks/.+", method: "GET", action: "network_inspect"},
-	{pattern: "/networks", method: "GET", action: "network_list"},
-	{pattern: "/networks/create", method: "POST", action: "network_create"},
-	{pattern: "/networks/.+/connect", method: "POST", action: "network_connect"},
-	{pattern: "/networks/.+/disconnect", method: "POST", action: "network_disconnect"},
-	{pattern: "/networks/.+", method: "DELETE", action: "network_remove"},
+	{pattern: "/networks/{id}", method: "GET", action: "network_inspect", class: ClassRead},
+	{pattern: "/networks", method: "GET", action: "network_list", class: ClassRead},
+	{pattern: "/networks/create", method: "POST", action: "network_create", class: ClassWrite},
+	{pattern: "/networks/{id}/connect", method: "POST", action: "network_connect", class: ClassWrite},
+	{pattern: "/networks/{id}/disconnect", method: "POST", action: "network_disconnect", class: ClassWrite},
+	{pattern: "/networks/{id}", method: "DELETE", action: "network_remove", class: ClassWrite},
 }
 
 // container routes
 var containerRoutes = []route{
-	{pattern: "/commit", method: "POST", action: "container_commit"},
-	{pattern: "/containers/.+/wait", method: "POST", action: "container_wait"},
-	{pattern: "/containers/.+/resize", method: "POST", action: "container_resize"},
-	{pattern: "/containers/.+/export", method: "GET", action: "container_export"},
-	{pattern: "/containers/.+/stop", method: "POST", action: "container_stop"},
-	{pattern: "/containers/.+/kill", method: "POST", action: "container_kill"},
-	{pattern: "/containers/.+/restart", method: "POST", action: "container_restart"},
-	{pattern: "/containers/.+/start", method: "POST", action: "container_start"},
-	{pattern: "/containers/.+/update", method: "POST", action: "container_update"},
-	{pattern: "/containers/.+/exec", method: "POST", action: "container_exec_create"},
-	{pattern: "/containers/.+/unpause", method: "POST", action: "container_unpause"},
-	{pattern: "/containers/.+/pause", method: "POST", action: "container_pause"},
-	{pattern: "/containers/.+/copy", method: "POST", action: "container_copyfiles"},
-	{pattern: "/containers/.+/archive", method: "PUT", action: "container_archive_extract"},
-	{pattern: "/containers/.+/archive", method: "HEAD", action: "container_archive_info"},
-	{pattern: "/containers/.+/archive", method: "GET", action: "container_archive"},
-	{pattern: "/containers/.+/attach/ws", method: "GET", action: "container_attach_websocket"},
-	{pattern: "/containers/.+/attach", method: "POST", action: "container_attach"},
-	{pattern: "/containers/json", method: "GET", action: "container_list"},
-	{pattern: "/containers/.+/json", method: "GET", action: "container_inspect"},
-	{pattern: "/containers/.+", method: "DELETE", action: "container_delete"},
-	{pattern: "/containers/.+/rename", method: "POST", action: "container_rename"},
-	{pattern: "/containers/.+/stats", method: "GET", action: "container_stats"},
-	{pattern: "/containers/.+/changes", method: "GET", action: "container_changes"},
-	{pattern: "/containers/.+/top", method: "GET", action: "container_top"},
-	{pattern: "/containers/.+/logs", method: "GET", action: "container_logs"},
-	{pattern: "/containers/create", method: "POST", action: "container_create"},
-	{pattern: "/exec/.+/json", method: "GET", action: "container_exec_inspect"},
-	{pattern: "/exec/.+/start", method: "POST", action: "container_exec_start"},
+	{pattern: "/commit", method: "POST", action: "container_commit", class: ClassWrite},
+	{pattern: "/containers/{name}/wait", method: "POST", action: "container_wait", class: ClassRead},
+	{pattern: "/containers/{name}/resize", method: "POST", action: "container_resize", class: ClassWrite},
+	{pattern: "/containers/{name}/export", method: "GET", action: "container_export", class: ClassSensitiveRead},
+	{pattern: "/containers/{name}/stop", method: "POST", action: "container_stop", class: ClassWrite},
+	{pattern: "/containers/{name}/kill", method: "POST", action: "container_kill", class: ClassWrite},
+	{pattern: "/containers/{name}/restart", method: "POST", action: "container_restart", class: ClassWrite},
+	{pattern: "/containers/{name}/start", method: "POST", action: "container_start", class: ClassWrite},
+	{pattern: "/containers/{name}/update", method: "POST", action: "container_update", class: ClassWrite},
+	{pattern: "/containers/{name}/exec", method: "POST", action: "container_exec_create", class: ClassExec},
+	{pattern: "/containers/{name}/unpause", method: "POST", action: "container_unpause", class: ClassWrite},
+	{pattern: "/containers/{name}/pause", method: "POST", action: "container_pause", class: ClassWrite},
+	{pattern: "/containers/{name}/copy", method: "POST", action: "container_copyfiles", class: ClassSensitiveRead, maxVersion: "1.23"},
+	{pattern: "/containers/{name}/archive", method: "PUT", action: "container_archive_extract", class: ClassWrite, minVersion: "1.20"},
+	{pattern: "/containers/{name}/archive", method: "HEAD", action: "container_archive_info", class: ClassRead, minVersion: "1.20"},
+	{pattern: "/containers/{name}/archive", method: "GET", action: "container_archive", class: ClassSensitiveRead, minVersion: "1.20"},
+	{pattern: "/containers/{name}/attach/ws", method: "GET", action: "container_attach_websocket", class: ClassExec},
+	{pattern: "/containers/{name}/attach", method: "POST", action: "container_attach", class: ClassExec},
+	{pattern: "/containers/json", method: "GET", action: "container_list", class: ClassRead},
+	{pattern: "/containers/{name}/json", method: "GET", action: "container_inspect", class: ClassRead},
+	{pattern: "/containers/{name}", method: "DELETE", action: "container_delete", class: ClassWrite},
+	{pattern: "/containers/{name}/rename", method: "POST", action: "container_rename", class: ClassWrite},
+	{pattern: "/containers/{name}/stats", method: "GET", action: "container_stats", class: ClassRead},
+	{pattern: "/containers/{name}/changes", method: "GET", action: "container_changes", class: ClassRead},
+	{pattern: "/containers/{name}/top", method: "GET", action: "container_top", class: ClassRead},
+	{pattern: "/containers/{name}/logs", method: "GET", action: "container_logs", class: ClassRead},
+	{pattern: "/containers/create", method: "POST", action: "container_create", class: ClassWrite},
+	{pattern: "/exec/{id}/json", method: "GET", action: "container_exec_inspect", class: ClassRead},
+	{pattern: "/exec/{id}/start", method: "POST", action: "container_exec_start", class: ClassExec},
 }
 
 var routes = []routeslice{
@@ -110,20 +216,57 @@ var routes = []routeslice{
 	containerRoutes,
 }
 
-// ParseRoute convert a method/url pattern to corresponding isulad action
+// builtinRouter resolves the builtin routes, the routes file only applies to
+// the routers of the authorizer snapshots
+var builtinRouter = newRouter(routes)
+
+// ParseRoute convert a method/url pattern to corresponding isulad action,
+// UnknownAction is returned if no route matches
 func ParseRoute(method, url string) string {
-	for _, rs := range routes {
-		for _, route := range rs {
-			if route.method == method {
-				pattern := strings.Replace(route.pattern, ".+", "[a-zA-Z0-9_.:/-]+", 1) + "$"
-				url = strings.Split(url, "?")[0]
-				match, err := regexp.MatchString(pattern, url)
-				if err == nil && match {
-					return route.action
-				}
+	return ResolveRoute(method, url).Action
+}
 
-			}
+// ResolveRoute converts a method/url pattern to the corresponding isulad
+// action and api version of the builtin routes. Unversioned urls match
+// routes of every version.
+func ResolveRoute(method, url string) RouteMatch {
+	return builtinRouter.resolve(method, url, "")
+}
+
+// normalizeURI converts a request uri to the canonical path the daemon routes
+// on and returns the api version prefix stripped from it, if any. The prefix
+// is stripped as the daemon does, even if it is not a well formed version.
+func normalizeURI(uri string) (string, string, error) {
+	if i := strings.IndexAny(uri, "?#"); i >= 0 {
+		uri = uri[:i]
+	}
+	if !strings.HasPrefix(uri, "/") {
+		return "", "", fmt.Errorf("uri is not an absolute path")
+	}
+	p, err := url.PathUnescape(uri)
+	if err != nil {
+		return "", "", err
+	}
+	// the daemon unescapes the path once, anything still escaped is not a
+	// path the daemon would route and is rejected to avoid ambiguity
+	if strings.Contains(p, "%") {
+		return "", "", fmt.Errorf("uri is escaped more than once")
+	}
+	for _, c := range p {
+		if c < 0x20 || c == 0x7f {
+			return "", "", fmt.Errorf("uri contains control characters")
+		}
+	}
+	p = path.Clean(p)
+
+	version := ""
+	segments := strings.SplitN(strings.TrimPrefix(p, "/"), "/", 2)
+	if apiVersionPrefixRegexp.MatchString(segments[0]) {
+		version = strings.TrimPrefix(segments[0], "v")
+		p = "/"
+		if len(segments) > 1 {
+			p += segments[1]
 		}
 	}
-	return ""
+	return p, version, nil
 }
@@ -0,0 +1,156 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: route parser and bypass corpus tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import "testing"
+
+func TestParseRoute(t *testing.T) {
+	cases := []struct {
+		method string
+		uri    string
+		action string
+	}{
+		{"GET", "/containers/json", "container_list"},
+		{"GET", "/containers/json?all=1", "container_list"},
+		{"GET", "/containers/abc/json", "container_inspect"},
+		{"GET", "/containers/json/json", "container_inspect"},
+		{"POST", "/containers/create?name=abc", "container_create"},
+		{"DELETE", "/containers/abc", "container_delete"},
+		{"GET", "/containers/abc/attach/ws", "container_attach_websocket"},
+		{"HEAD", "/containers/abc/archive", "container_archive_info"},
+		{"GET", "/images/json", "image_list"},
+		{"GET", "/images/search?term=busybox", "images_search"},
+		{"GET", "/images/busybox/json", "image_inspect"},
+		{"GET", "/images/library/busybox:latest/json", "image_inspect"},
+		{"POST", "/images/registry.local:5000/team/app/push", "image_push"},
+		{"DELETE", "/images/library/busybox", "image_delete"},
+		{"GET", "/volumes", "volume_list"},
+		{"POST", "/volumes/create", "volume_create"},
+		{"GET", "/exec/123/json", "container_exec_inspect"},
+		{"POST", "/exec/123/start", "container_exec_start"},
+		{"GET", "/_ping", "isulad_ping"},
+		{"GET", "/v1.40/containers/abc/logs", "container_logs"},
+		{"GET", "/v1/info", "isulad_info"},
+		{"POST", "/containers/abc/copy", "container_copyfiles"},
+		{"POST", "/v1.23/containers/abc/copy", "container_copyfiles"},
+		{"POST", "/v1.24/containers/abc/copy", UnknownAction},
+		{"GET", "/v1.19/containers/abc/archive", UnknownAction},
+		{"GET", "/v1.20/containers/abc/archive", "container_archive"},
+		{"POST", "/containers/abc/foo", UnknownAction},
+		{"PATCH", "/containers/abc", UnknownAction},
+		{"GET", "/", UnknownAction},
+		{"GET", "", UnknownAction},
+	}
+	for _, c := range cases {
+		if action := ParseRoute(c.method, c.uri); action != c.action {
+			t.Errorf("ParseRoute(%q, %q) = %q, want %q", c.method, c.uri, action, c.action)
+		}
+	}
+}
+
+// TestParseRouteBypass checks crafted uris resolve to the action the daemon
+// would execute, or to UnknownAction, never to a different known action
+func TestParseRouteBypass(t *testing.T) {
+	cases := []struct {
+		method string
+		uri    string
+		action string
+	}{
+		// path canonicalization
+		{"POST", "//containers/abc/exec", "container_exec_create"},
+		{"POST", "/containers//abc/exec", "container_exec_create"},
+		{"POST", "/containers/abc/exec/", "container_exec_create"},
+		{"POST", "/containers/abc/exec//", "container_exec_create"},
+		{"POST", "/containers/./abc/exec", "container_exec_create"},
+		{"POST", "/containers/abc/./exec", "container_exec_create"},
+		{"POST", "/containers/abc/json/../exec", "container_exec_create"},
+		{"POST", "/../containers/abc/exec", "container_exec_create"},
+		{"GET", "/containers/abc/logs/..", UnknownAction},
+		// percent encoding
+		{"POST", "/containers/abc/%65xec", "container_exec_create"},
+		{"POST", "/containers/abc%2Fexec", "container_exec_create"},
+		{"POST", "/%63ontainers/abc/exec", "container_exec_create"},
+		{"POST", "/containers/abc/exec%3Fx=/json", UnknownAction},
+		{"POST", "/containers/abc/exec%2500", UnknownAction},
+		{"POST", "/containers/abc/exec%00", UnknownAction},
+		{"POST", "/containers/abc/exec%zz", UnknownAction},
+		{"GET", "/containers/abc/%2e%2e/json", "container_list"},
+		// query strings and fragments
+		{"POST", "/containers/abc/exec?x=/json", "container_exec_create"},
+		{"POST", "/containers/abc/exec#/json", "container_exec_create"},
+		{"GET", "/containers/abc/json?/exec", "container_inspect"},
+		// api version prefixes
+		{"POST", "/v1.40/containers/abc/exec", "container_exec_create"},
+		{"POST", "/v1.40//containers/abc/exec", "container_exec_create"},
+		{"POST", "/v1.40/v1.40/containers/abc/exec", UnknownAction},
+		{"POST", "/v1.x/containers/abc/exec", UnknownAction},
+		{"POST", "/v1/containers/abc/exec", "container_exec_create"},
+		{"POST", "/v1.40.1/containers/abc/exec", "container_exec_create"},
+		{"POST", "/v1..40/containers/abc/exec", UnknownAction},
+		{"POST", "/v1.40./containers/abc/exec", UnknownAction},
+		{"POST", "/v.1/containers/abc/exec", UnknownAction},
+		{"POST", "/v./containers/abc/exec", UnknownAction},
+		// parameters spanning segments
+		{"DELETE", "/containers/abc/exec", UnknownAction},
+		{"DELETE", "/volumes/abc/def", UnknownAction},
+		{"GET", "/networks/abc/def", UnknownAction},
+		{"GET", "/images/abc/get/json", "image_inspect"},
+		{"POST", "/images/abc/json/push", "image_push"},
+		// relative and absolute form uris
+		{"POST", "containers/abc/exec", UnknownAction},
+		{"POST", "http://host/containers/abc/exec", UnknownAction},
+	}
+	for _, c := range cases {
+		if action := ParseRoute(c.method, c.uri); action != c.action {
+			t.Errorf("ParseRoute(%q, %q) = %q, want %q", c.method, c.uri, action, c.action)
+		}
+	}
+}
+
+func TestCompareAPIVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.40", "1.40", 0},
+		{"1.9", "1.10", -1},
+		{"1.24", "1.23", 1},
+		{"2", "1.99", 1},
+		{"1", "1.0", 0},
+		{"1.40.1", "1.40", 1},
+		{"1.40.0", "1.40", 0},
+	}
+	for _, c := range cases {
+		if got := compareAPIVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareAPIVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestResolveRouteResource(t *testing.T) {
+	for _, c := range []struct {
+		method, uri, resource string
+	}{
+		{"GET", "/containers/json", ""},
+		{"POST", "/v1.40/containers/web-1/start", "web-1"},
+		{"GET", "/images/registry.local:5000/team/app/json", "registry.local:5000/team/app"},
+		{"DELETE", "/images/busybox", "busybox"},
+		{"POST", "/exec/123/start", "123"},
+		{"POST", "/containers/abc/foo", ""},
+	} {
+		if resource := ResolveRoute(c.method, c.uri).Resource; resource != c.resource {
+			t.Errorf("ResolveRoute(%q, %q) resource = %q, want %q", c.method, c.uri, resource, c.resource)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: match normalized request paths against route templates
+//              segment by segment
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type segment struct {
+	literal string // literal is the segment text, empty for parameters
+	param   bool   // param matches exactly one segment
+	multi   bool   // multi matches one or more segments
+}
+
+type compiledRoute struct {
+	route
+	segments []segment
+	literals int // literals is the number of literal segments, used to rank matches
+	order    int // order is the position of the route in the table, used to rank matches
+}
+
+// node is a trie node of route templates, one level per path segment
+type node struct {
+	literals map[string]*node
+	param    *node
+	multi    *node
+	routes   []*compiledRoute // routes are the routes whose template ends at this node
+}
+
+func (n *node) child(s segment) *node {
+	next := &n.param
+	switch {
+	case s.multi:
+		next = &n.multi
+	case !s.param:
+		if n.literals == nil {
+			n.literals = make(map[string]*node)
+		}
+		if c, ok := n.literals[s.literal]; ok {
+			return c
+		}
+		c := &node{}
+		n.literals[s.literal] = c
+		return c
+	}
+	if *next == nil {
+		*next = &node{}
+	}
+	return *next
+}
+
+type router struct {
+	routes  []*compiledRoute
+	methods map[string]*node  // methods are the trie roots per http method
+	actions map[string]string // actions maps actions to the class of their first route
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// validateTemplate checks a route path template is an absolute path of
+// non-empty segments with well formed parameters
+func validateTemplate(pattern string) error {
+	if !strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("path %q is not absolute", pattern)
+	}
+	for _, s := range splitPath(pattern) {
+		switch {
+		case s == "" || s == "." || s == "..":
+			return fmt.Errorf("path %q has an empty or relative segment", pattern)
+		case strings.HasPrefix(s, "{") != strings.HasSuffix(s, "}"):
+			return fmt.Errorf("path %q has malformed parameter %q", pattern, s)
+		case s == "{}" || s == "{*}":
+			return fmt.Errorf("path %q has unnamed parameter", pattern)
+		case strings.ContainsAny(strings.TrimSuffix(strings.Trim(s, "{}"), "*"), "{}*"):
+			return fmt.Errorf("path %q has malformed segment %q", pattern, s)
+		}
+	}
+	return nil
+}
+
+// templateKey returns the shape of a path template, templates differing only
+// in parameter names have the same key
+func templateKey(method, pattern string) string {
+	c := compileRoute(route{pattern: pattern})
+	var b strings.Builder
+	b.WriteString(method)
+	for _, s := range c.segments {
+		b.WriteString("/")
+		switch {
+		case s.multi:
+			b.WriteString("{*}")
+		case s.param:
+			b.WriteString("{}")
+		default:
+			b.WriteString(s.literal)
+		}
+	}
+	return b.String()
+}
+
+func compileRoute(r route) *compiledRoute {
+	c := &compiledRoute{route: r}
+	for _, s := range splitPath(r.pattern) {
+		switch {
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "*}"):
+			c.segments = append(c.segments, segment{multi: true})
+		case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			c.segments = append(c.segments, segment{param: true})
+		default:
+			c.segments = append(c.segments, segment{literal: s})
+			c.literals++
+		}
+	}
+	return c
+}
+
+func newRouter(tables []routeslice) *router {
+	rt := &router{
+		methods: make(map[string]*node),
+		actions: make(map[string]string),
+	}
+	for _, rs := range tables {
+		for _, r := range rs {
+			c := compileRoute(r)
+			c.order = len(rt.routes)
+			rt.routes = append(rt.routes, c)
+			if _, ok := rt.actions[c.action]; !ok {
+				rt.actions[c.action] = c.routeClass()
+			}
+
+			n, ok := rt.methods[c.method]
+			if !ok {
+				n = &node{}
+				rt.methods[c.method] = n
+			}
+			for _, s := range c.segments {
+				n = n.child(s)
+			}
+			n.routes = append(n.routes, c)
+		}
+	}
+	return rt
+}
+
+// better reports whether route a ranks before route b
+func better(a, b *compiledRoute) bool {
+	if b == nil || a.literals != b.literals {
+		return b == nil || a.literals > b.literals
+	}
+	return a.order < b.order
+}
+
+// walk descends the trie along segs and returns the best route of version
+// ending where segs end
+func (n *node) walk(segs []string, version string, best *compiledRoute) *compiledRoute {
+	if len(segs) == 0 {
+		for _, r := range n.routes {
+			if version != "" && !apiVersionInRange(version, r.minVersion, r.maxVersion) {
+				continue
+			}
+			if better(r, best) {
+				best = r
+			}
+		}
+		return best
+	}
+	if c, ok := n.literals[segs[0]]; ok {
+		best = c.walk(segs[1:], version, best)
+	}
+	if n.param != nil {
+		best = n.param.walk(segs[1:], version, best)
+	}
+	if n.multi != nil {
+		for i := len(segs); i >= 1; i-- {
+			best = n.multi.walk(segs[i:], version, best)
+		}
+	}
+	return best
+}
+
+// lookup returns the route matching the normalized path and api version,
+// routes with more literal segments take precedence, then the earlier route
+// in the table. An empty version matches routes of every version.
+func (rt *router) lookup(method, p, version string) *compiledRoute {
+	root, ok := rt.methods[method]
+	if !ok {
+		return nil
+	}
+	return root.walk(splitPath(p), version, nil)
+}
+
+// resolve converts a method/url pattern to the corresponding route match.
+// Routes of urls without api version prefix are looked up for the assumed
+// version, if empty they match routes of every version. Urls with a malformed
+// version prefix match no route.
+func (rt *router) resolve(method, url, assumed string) RouteMatch {
+	p, version, err := normalizeURI(url)
+	if err != nil {
+		logrus.Warnf("Failed to normalize url %q: %v", url, err)
+		return RouteMatch{Action: UnknownAction}
+	}
+	match := RouteMatch{Action: UnknownAction, APIVersion: version}
+	if version != "" && ParseAPIVersion(version) != nil {
+		return match
+	}
+	if version == "" {
+		version = assumed
+	}
+	if r := rt.lookup(method, p, version); r != nil {
+		match.Action = r.action
+		match.Class = r.routeClass()
+		match.Resource = r.resource(splitPath(p))
+	}
+	return match
+}
+
+// resource returns the segments matching the first parameter of the route,
+// segs must match the route
+func (r *compiledRoute) resource(segs []string) string {
+	for i, s := range r.segments {
+		switch {
+		case s.param:
+			return segs[i]
+		case s.multi:
+			// the segments after a multi parameter are literals
+			return strings.Join(segs[i:len(segs)-(len(r.segments)-i-1)], "/")
+		}
+	}
+	return ""
+}
+
+// actionClass returns the class of the first route mapped to action
+func (rt *router) actionClass(action string) string {
+	return rt.actions[action]
+}
+
+// actionVersions returns the api version range over all routes of action,
+// a bound is empty if any route leaves it open
+func (rt *router) actionVersions(action string) (string, string) {
+	min, max := "", ""
+	first, openMin, openMax := true, false, false
+	for _, r := range rt.routes {
+		if r.action != action {
+			continue
+		}
+		openMin = openMin || r.minVersion == ""
+		openMax = openMax || r.maxVersion == ""
+		if first || (r.minVersion != "" && compareAPIVersions(r.minVersion, min) < 0) {
+			min = r.minVersion
+		}
+		if first || (r.maxVersion != "" && compareAPIVersions(r.maxVersion, max) > 0) {
+			max = r.maxVersion
+		}
+		first = false
+	}
+	if openMin {
+		min = ""
+	}
+	if openMax {
+		max = ""
+	}
+	return min, max
+}
+
+// matchesAny reports whether re matches any action of the route table
+func (rt *router) matchesAny(re *regexp.Regexp) bool {
+	for action := range rt.actions {
+		if re.MatchString(action) {
+			return true
+		}
+	}
+	return false
+}
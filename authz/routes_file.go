@@ -0,0 +1,139 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: load routes that add, override or disable builtin routes
+//              from a yaml or json file
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	methodRegexp = regexp.MustCompile(`^[A-Z]+$`)
+	actionRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+)
+
+// RouteConfig is a route entry of the routes file. An entry with the same
+// method and path template as a builtin route overrides or disables it,
+// other entries add new routes.
+type RouteConfig struct {
+	Method        string `yaml:"method"`        // Method is the http method
+	Path          string `yaml:"path"`          // Path is the path template, e.g. /containers/{name}/start
+	Action        string `yaml:"action"`        // Action is the isulad action name
+	Class         string `yaml:"class"`         // Class is the route class, e.g. read, write or exec
+	MinAPIVersion string `yaml:"minApiVersion"` // MinAPIVersion is the lowest api version the route matches
+	MaxAPIVersion string `yaml:"maxApiVersion"` // MaxAPIVersion is the highest api version the route matches
+	Disable       bool   `yaml:"disable"`       // Disable removes the builtin route
+}
+
+type routesFile struct {
+	Routes []RouteConfig `yaml:"routes"`
+}
+
+func (rc *RouteConfig) validate() error {
+	if !methodRegexp.MatchString(rc.Method) {
+		return fmt.Errorf("invalid method %q", rc.Method)
+	}
+	if err := validateTemplate(rc.Path); err != nil {
+		return err
+	}
+	if rc.Disable {
+		return nil
+	}
+	if !actionRegexp.MatchString(rc.Action) || rc.Action == UnknownAction {
+		return fmt.Errorf("invalid action %q", rc.Action)
+	}
+	if rc.Class != "" && !validClass(rc.Class) {
+		return fmt.Errorf("invalid class %q", rc.Class)
+	}
+	for _, v := range []string{rc.MinAPIVersion, rc.MaxAPIVersion} {
+		if v == "" {
+			continue
+		}
+		if err := ParseAPIVersion(v); err != nil {
+			return err
+		}
+	}
+	if rc.MinAPIVersion != "" && rc.MaxAPIVersion != "" && compareAPIVersions(rc.MinAPIVersion, rc.MaxAPIVersion) > 0 {
+		return fmt.Errorf("min api version %s is higher than max api version %s", rc.MinAPIVersion, rc.MaxAPIVersion)
+	}
+	return nil
+}
+
+// parseRoutesFile parses the data of a routes file and merges it with the
+// builtin routes
+func parseRoutesFile(routesPath string, data []byte) ([]routeslice, error) {
+	var file routesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse routes file %s: %v", routesPath, err)
+	}
+	return mergeRoutes(routes, file.Routes)
+}
+
+// mergeRoutes applies route entries to the builtin tables. Added routes are
+// placed before the builtin ones so they win ties against them.
+func mergeRoutes(builtin []routeslice, entries []RouteConfig) ([]routeslice, error) {
+	var merged routeslice
+	index := make(map[string]int)
+	for _, rs := range builtin {
+		for _, r := range rs {
+			index[templateKey(r.method, r.pattern)] = len(merged)
+			merged = append(merged, r)
+		}
+	}
+
+	disabled := make(map[int]bool)
+	seen := make(map[string]bool)
+	var added routeslice
+	for i, rc := range entries {
+		if err := rc.validate(); err != nil {
+			return nil, fmt.Errorf("route %d (%s %s): %v", i+1, rc.Method, rc.Path, err)
+		}
+		key := templateKey(rc.Method, rc.Path)
+		if seen[key] {
+			return nil, fmt.Errorf("route %d (%s %s): duplicate route", i+1, rc.Method, rc.Path)
+		}
+		seen[key] = true
+
+		r := route{
+			pattern:    rc.Path,
+			method:     rc.Method,
+			action:     rc.Action,
+			class:      rc.Class,
+			minVersion: rc.MinAPIVersion,
+			maxVersion: rc.MaxAPIVersion,
+		}
+		j, ok := index[key]
+		switch {
+		case ok && rc.Disable:
+			disabled[j] = true
+		case ok:
+			merged[j] = r
+		case rc.Disable:
+			return nil, fmt.Errorf("route %d (%s %s): no builtin route to disable", i+1, rc.Method, rc.Path)
+		default:
+			added = append(added, r)
+		}
+	}
+
+	var kept routeslice
+	for j, r := range merged {
+		if !disabled[j] {
+			kept = append(kept, r)
+		}
+	}
+	return []routeslice{added, kept}, nil
+}
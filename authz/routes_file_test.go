@@ -0,0 +1,143 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: routes file tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+const testRoutesFile = `routes:
+- method: POST
+  path: /containers/{name}/checkpoint
+  action: container_checkpoint
+  class: write
+- method: GET
+  path: /containers/{name}/logs
+  action: container_logs_override
+  class: sensitive-read
+- method: GET
+  path: /containers/{id}/export
+  disable: true
+- method: GET
+  path: /plugins/{name*}/json
+  action: plugin_inspect
+  minApiVersion: "1.30"
+`
+
+func TestParseRoutesFile(t *testing.T) {
+	tables, err := parseRoutesFile("routes.yaml", []byte(testRoutesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := newRouter(tables)
+	cases := []struct {
+		method string
+		uri    string
+		action string
+		class  string
+	}{
+		{"POST", "/containers/abc/checkpoint", "container_checkpoint", ClassWrite},
+		{"GET", "/containers/abc/logs", "container_logs_override", ClassSensitiveRead},
+		{"GET", "/containers/abc/export", UnknownAction, ""},
+		{"GET", "/v1.30/plugins/vendor/name/json", "plugin_inspect", ClassRead},
+		{"GET", "/v1.29/plugins/vendor/name/json", UnknownAction, ""},
+		{"GET", "/containers/json", "container_list", ClassRead},
+		{"POST", "/containers/abc/start", "container_start", ClassWrite},
+	}
+	for _, c := range cases {
+		m := rt.resolve(c.method, c.uri, "")
+		if m.Action != c.action || m.Class != c.class {
+			t.Errorf("%s %s resolved to %q (%q), want %q (%q)", c.method, c.uri, m.Action, m.Class, c.action, c.class)
+		}
+	}
+	if _, ok := rt.actions["container_logs"]; ok {
+		t.Error("overridden action container_logs still in the route table")
+	}
+	if _, ok := rt.actions["container_export"]; ok {
+		t.Error("disabled action container_export still in the route table")
+	}
+}
+
+func TestMergeRoutesAddedWinTies(t *testing.T) {
+	tables, err := mergeRoutes(routes, []RouteConfig{
+		{Method: "GET", Path: "/containers/{name}/{sub}", Action: "container_sub", Class: ClassRead},
+		{Method: "GET", Path: "/containers/{id}/inspect", Action: "container_inspect2", Class: ClassRead},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := newRouter(tables)
+	if action := rt.resolve("GET", "/containers/abc/inspect", "").Action; action != "container_inspect2" {
+		t.Errorf("added route resolved to %q, want container_inspect2", action)
+	}
+	// routes with more literal segments still win over added ones
+	if action := rt.resolve("GET", "/containers/abc/json", "").Action; action != "container_inspect" {
+		t.Errorf("builtin route resolved to %q, want container_inspect", action)
+	}
+	if action := rt.resolve("GET", "/containers/abc/other", "").Action; action != "container_sub" {
+		t.Errorf("added route resolved to %q, want container_sub", action)
+	}
+}
+
+func TestParseRoutesFileErrors(t *testing.T) {
+	cases := []struct {
+		data string
+		err  string
+	}{
+		{"routes:\n- method: get\n  path: /x\n  action: x\n", `invalid method "get"`},
+		{"routes:\n- method: GET\n  path: x\n  action: x\n", `path "x" is not absolute`},
+		{"routes:\n- method: GET\n  path: /x/../y\n  action: x\n", "empty or relative segment"},
+		{"routes:\n- method: GET\n  path: /x/{name\n  action: x\n", "malformed parameter"},
+		{"routes:\n- method: GET\n  path: /x/{}\n  action: x\n", "unnamed parameter"},
+		{"routes:\n- method: GET\n  path: /x/{a*b}\n  action: x\n", "malformed segment"},
+		{"routes:\n- method: GET\n  path: /x\n  action: x y\n", `invalid action "x y"`},
+		{"routes:\n- method: GET\n  path: /x\n  action: unknown\n", `invalid action "unknown"`},
+		{"routes:\n- method: GET\n  path: /x\n  action: x\n  class: root\n", `invalid class "root"`},
+		{"routes:\n- method: GET\n  path: /x\n  action: x\n  minApiVersion: \"1.x\"\n", `invalid api version "1.x"`},
+		{"routes:\n- method: GET\n  path: /x\n  action: x\n  minApiVersion: \"1.40\"\n  maxApiVersion: \"1.30\"\n", "min api version 1.40 is higher"},
+		{"routes:\n- method: GET\n  path: /x\n  disable: true\n", "no builtin route to disable"},
+		{"routes:\n- method: GET\n  path: /x/{a}\n  action: x\n- method: GET\n  path: /x/{b}\n  action: y\n", "route 2 (GET /x/{b}): duplicate route"},
+		{"routes:\n- method: GET\n  path: /x\n  action: x\n  verb: read\n", "field verb not found"},
+	}
+	for _, c := range cases {
+		_, err := parseRoutesFile("routes.yaml", []byte(c.data))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%q: got error %v, want %q", c.data, err, c.err)
+		}
+	}
+}
+
+func TestRoutesFileAppliesToSnapshotOnly(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["container_checkpoint"]}`+"\n")
+	routesPath := filepath.Join(filepath.Dir(p), "routes.yaml")
+	if err := ioutil.WriteFile(routesPath, []byte(testRoutesFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f := NewAuthorizer(&Config{PolicyPath: p, RoutesPath: routesPath})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	req := &authorization.Request{User: "alice", RequestMethod: "POST", RequestURI: "/v1.40/containers/abc/checkpoint"}
+	if d := f.DecideRequest(req); !d.Allowed() || d.Action != "container_checkpoint" {
+		t.Errorf("unexpected decision %+v", d)
+	}
+	if action := ParseRoute(req.RequestMethod, req.RequestURI); action != UnknownAction {
+		t.Errorf("routes file leaked into the builtin routes, resolved to %q", action)
+	}
+}
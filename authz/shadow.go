@@ -0,0 +1,145 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: candidate policies evaluated alongside the enforcing ones
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"log/syslog"
+	"os"
+	"sync/atomic"
+
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/sirupsen/logrus"
+	logrus_syslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// ShadowStatus reports the shadow policies and how often they decided a
+// request differently than the enforcing policies
+type ShadowStatus struct {
+	PolicyStatus
+	Requests      uint64 `json:"requests"`      // Requests is the number of requests evaluated
+	Disagreements uint64 `json:"disagreements"` // Disagreements is the number of requests decided differently
+}
+
+// shadow decides every request with the candidate policies as well and
+// audits the requests they decide differently, it never changes a response
+type shadow struct {
+	// counters first, 64 bit atomics need 64 bit alignment on 32 bit platforms
+	requests      uint64
+	disagreements uint64
+
+	*authorizer
+	logger *logrus.Logger
+	out    *os.File // out is the shadow audit file, nil if auditing to syslog
+}
+
+// newShadow returns the shadow of the configured candidate policies, nil if
+// there are none. They are loaded with the settings of the enforcing ones.
+func newShadow(config *Config) *shadow {
+	if config.ShadowPolicyPath == "" && config.ShadowPolicyDir == "" {
+		return nil
+	}
+	c := *config
+	c.PolicyPath, c.PolicyDir = config.ShadowPolicyPath, config.ShadowPolicyDir
+	c.ShadowPolicyPath, c.ShadowPolicyDir, c.ShadowAuditPath = "", "", ""
+
+	logger := logrus.New()
+	logger.Formatter = &logrus.JSONFormatter{}
+	return &shadow{
+		authorizer: &authorizer{
+			config:    c,
+			store:     newPolicyStore(&snapshot{engine: newEngine(&c, newRouter(routes), nil)}),
+			candidate: true,
+			done:      make(chan struct{}),
+		},
+		logger: logger,
+	}
+}
+
+// init opens the shadow audit stream, the audit file if configured and the
+// syslog with tag authz-shadow otherwise
+func (s *shadow) init(auditPath string) error {
+	if auditPath != "" {
+		f, err := os.OpenFile(auditPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+		if err != nil {
+			return err
+		}
+		s.logger.Out = f
+		s.out = f
+		return nil
+	}
+	hook, err := logrus_syslog.NewSyslogHook("", "", syslog.LOG_ERR, "authz-shadow")
+	if err != nil {
+		return err
+	}
+	s.logger.Hooks.Add(hook)
+	return nil
+}
+
+// Close stops watching the shadow policy files and closes the shadow audit
+// file
+func (s *shadow) Close() error {
+	err := s.authorizer.Close()
+	if s.out != nil {
+		if cerr := s.out.Close(); err == nil {
+			err = cerr
+		}
+		s.out = nil
+	}
+	return err
+}
+
+// compare decides request with the shadow policies and audits it if the
+// enforced decision differs. Records have the fields of the request audit,
+// so replay reads them as well. Nothing is compared until the shadow
+// policies loaded once.
+func (s *shadow) compare(request *authorization.Request, enforced *Decision) {
+	defer func() {
+		if r := recover(); r != nil {
+			logrus.Errorf("Shadow policy evaluation failed: %v", r)
+		}
+	}()
+	snap := s.store.load()
+	if snap.hash == "" {
+		return
+	}
+	atomic.AddUint64(&s.requests, 1)
+	d := snap.engine.decideRequest(request)
+	if d.Allowed() == enforced.Allowed() {
+		return
+	}
+	atomic.AddUint64(&s.disagreements, 1)
+	s.logger.WithFields(logrus.Fields{
+		"method":       request.RequestMethod,
+		"uri":          request.RequestURI,
+		"action":       enforced.Action,
+		"user":         request.User,
+		"allow":        enforced.Allowed(),
+		"msg":          enforced.Message,
+		"policy":       enforced.Policy,
+		"shadowAllow":  d.Allowed(),
+		"shadowPolicy": d.Policy,
+		"shadowRule":   d.Rule,
+		"shadowReason": d.Reason,
+		"shadowMsg":    d.Message,
+	}).Info("Shadow policy disagrees")
+}
+
+// status reports the shadow policies and the disagreement counts
+func (s *shadow) status() *ShadowStatus {
+	return &ShadowStatus{
+		PolicyStatus:  *s.store.status(),
+		Requests:      atomic.LoadUint64(&s.requests),
+		Disagreements: atomic.LoadUint64(&s.disagreements),
+	}
+}
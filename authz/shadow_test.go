@@ -0,0 +1,122 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: shadow policy tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+func TestShadowPolicies(t *testing.T) {
+	var audit bytes.Buffer
+	f := NewAuthorizer(&Config{
+		PolicyPath:       writePolicyFile(t, storePolicyA),
+		ShadowPolicyPath: writePolicyFile(t, storePolicyB),
+	}).(*authorizer)
+	f.shadow.logger.Out = &audit
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uri := range []string{"/v1.40/containers/abc/stop", "/v1.40/containers/abc/start"} {
+		resp, _ := f.AuthZRequest(&authorization.Request{User: "alice", RequestMethod: "POST", RequestURI: uri})
+		if !resp.Allow {
+			t.Fatalf("shadow policies changed the response for %s: %+v", uri, resp)
+		}
+	}
+	// the shadow policies agree
+	f.AuthZRequest(&authorization.Request{User: "alice", RequestMethod: "GET", RequestURI: "/v1.40/containers/json"})
+
+	records, skipped, err := ReadAuditLog(&audit, "shadow.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || skipped != 0 {
+		t.Fatalf("%d disagreements audited, %d lines skipped:\n%s", len(records), skipped, audit.String())
+	}
+	if r := records[1]; r.URI != "/v1.40/containers/abc/start" || r.Action != "container_start" || !r.Allow {
+		t.Errorf("unexpected disagreement %+v", r)
+	}
+	status := f.Status().Shadow
+	if status == nil || status.Policies != 2 || status.Requests != 3 || status.Disagreements != 2 {
+		t.Errorf("unexpected shadow status %+v", status)
+	}
+}
+
+func TestShadowPoliciesFailing(t *testing.T) {
+	f := NewAuthorizer(&Config{
+		PolicyPath:       writePolicyFile(t, storePolicyA),
+		ShadowPolicyPath: writePolicyFile(t, `{"name":`),
+	})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatalf("invalid shadow policies failed the enforcing ones: %v", err)
+	}
+	if resp, _ := f.AuthZRequest(&authorization.Request{User: "alice", RequestMethod: "GET", RequestURI: "/v1.40/info"}); !resp.Allow {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if status := f.Status().Shadow; status.LastError == "" || status.Requests != 0 {
+		t.Errorf("unexpected shadow status %+v", status)
+	}
+}
+
+// TestShadowPoliciesLearning checks the shadow policies are compared with the
+// decisions of the policies, not with the requests learning mode allows
+func TestShadowPoliciesLearning(t *testing.T) {
+	var audit, log bytes.Buffer
+	f := NewAuthorizer(&Config{
+		PolicyPath:       writePolicyFile(t, storePolicyB),
+		ShadowPolicyPath: writePolicyFile(t, storePolicyB),
+		LearningPath:     "learning.log",
+	}).(*authorizer)
+	f.shadow.logger.Out = &audit
+	f.learner.out = &log
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	resp, d := f.AuthZRequest(&authorization.Request{User: "alice", RequestMethod: "POST", RequestURI: "/v1.40/containers/abc/start"})
+	if !resp.Allow || d.Reason != ReasonLearning {
+		t.Fatalf("learning mode did not allow: %+v %+v", resp, d)
+	}
+	if status := f.Status().Shadow; status.Requests != 1 || status.Disagreements != 0 || audit.Len() != 0 {
+		t.Errorf("learning mode audited as disagreement %+v:\n%s", status, audit.String())
+	}
+}
+
+func TestShadowAuditFileClosed(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	f := NewAuthorizer(&Config{
+		PolicyPath:       p,
+		ShadowPolicyPath: p,
+		ShadowAuditPath:  filepath.Join(filepath.Dir(p), "shadow.log"),
+	}).(*authorizer)
+	if err := f.Init(); err != nil {
+		t.Fatal(err)
+	}
+	out := f.shadow.out
+	if out == nil {
+		t.Fatal("shadow audit file not opened")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Write([]byte("x")); err == nil {
+		t.Error("shadow audit file still open after close")
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("closing twice failed: %v", err)
+	}
+}
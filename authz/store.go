@@ -0,0 +1,138 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy store swapping immutable policy snapshots atomically
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// snapshot is an immutable set of loaded policies and routes, it must not
+// be modified once stored
+type snapshot struct {
+	engine   *engine
+	files    []string  // files are the policy and routes files the snapshot was loaded from
+	hash     string    // hash is the sha256 of the policy and routes files
+	loadedAt time.Time // loadedAt is when the snapshot was loaded
+}
+
+// PolicyStatus reports the active policies and the last failed reload
+type PolicyStatus struct {
+	Hash      string     `json:"hash"`                // Hash is the sha256 of the active policy and routes files
+	Policies  int        `json:"policies"`            // Policies is the number of active policies
+	LoadedAt  time.Time  `json:"loadedAt"`            // LoadedAt is when the active policies were loaded
+	LastError string     `json:"lastError,omitempty"` // LastError is why the last reload failed, empty if it succeeded
+	FailedAt  *time.Time `json:"failedAt,omitempty"`  // FailedAt is when the last reload failed
+
+	Shadow *ShadowStatus `json:"shadow,omitempty"` // Shadow reports the shadow policies if configured
+}
+
+// shortHash abbreviates a hash for logging
+func shortHash(hash string) string {
+	if hash == "" {
+		return "none"
+	}
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// statFiles returns the file info of all paths, nil for missing files
+func statFiles(paths []string) []os.FileInfo {
+	files := make([]os.FileInfo, len(paths))
+	for i, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			files[i] = fi
+		}
+	}
+	return files
+}
+
+// filesChanged reports whether any of paths has been replaced or modified
+// since files were taken
+func filesChanged(files []os.FileInfo, paths []string) bool {
+	if len(paths) != len(files) {
+		return true
+	}
+	for i, p := range paths {
+		old := files[i]
+		fi, err := os.Stat(p)
+		if err != nil || old == nil {
+			return (err == nil) != (old != nil)
+		}
+		if !os.SameFile(old, fi) || old.Size() != fi.Size() || !old.ModTime().Equal(fi.ModTime()) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyStore holds the current snapshot, readers never lock and always see
+// a complete snapshot while reloads are serialized
+type policyStore struct {
+	mu      sync.Mutex
+	current atomic.Value
+
+	// guarded by mu
+	seen     []os.FileInfo // seen are the files the last reload read, whether it failed or not
+	failure  error         // failure is why the last reload failed, nil if it succeeded
+	failedAt time.Time
+}
+
+func newPolicyStore(s *snapshot) *policyStore {
+	store := &policyStore{}
+	store.current.Store(s)
+	return store
+}
+
+// load returns the current snapshot
+func (p *policyStore) load() *snapshot {
+	return p.current.Load().(*snapshot)
+}
+
+// update builds a new snapshot with build and swaps it in on success, build
+// is called with the store locked so concurrent reloads do not interleave.
+// On failure the current snapshot stays active and the error is kept for
+// status, a nil snapshot without error means nothing changed
+func (p *policyStore) update(build func(old *snapshot) (*snapshot, error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, err := build(p.load())
+	if err != nil {
+		p.failure, p.failedAt = err, time.Now()
+		return err
+	}
+	if s == nil {
+		return nil
+	}
+	p.failure = nil
+	p.current.Store(s)
+	return nil
+}
+
+// status reports the current snapshot and the last failed reload
+func (p *policyStore) status() *PolicyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.load()
+	status := &PolicyStatus{Hash: s.hash, Policies: len(s.engine.policies), LoadedAt: s.loadedAt}
+	if p.failure != nil {
+		failedAt := p.failedAt
+		status.LastError, status.FailedAt = p.failure.Error(), &failedAt
+	}
+	return status
+}
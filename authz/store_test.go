@@ -0,0 +1,240 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy store reload and concurrency tests, run with -race
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+const (
+	storePolicyA = `{"name":"a","users":["alice"],"actions":["*"]}` + "\n"
+	storePolicyB = `{"name":"b","users":["alice"],"actions":["container_list"]}` + "\n" +
+		`{"name":"b2","users":["bob"],"actions":["*"]}` + "\n"
+)
+
+func writePolicyFile(t testing.TB, content string) string {
+	dir, err := ioutil.TempDir("", "authz-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	p := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestReloadOnlyOnChange(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	f := NewAuthorizer(&Config{PolicyPath: p}).(*authorizer)
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	first := f.store.load()
+	if err := f.reload(false); err != nil {
+		t.Fatal(err)
+	}
+	if f.store.load() != first {
+		t.Fatal("unchanged policy file reloaded")
+	}
+
+	if err := ioutil.WriteFile(p, []byte(storePolicyB), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.reload(false); err != nil {
+		t.Fatal(err)
+	}
+	if d := f.Decide("alice", "container_start"); d.Allowed() || d.Policy != "b" {
+		t.Fatalf("changed policy file not reloaded: %+v", d)
+	}
+}
+
+func TestReloadKeepsSnapshotOnError(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	f := NewAuthorizer(&Config{PolicyPath: p})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	hash := f.Status().Hash
+
+	for _, c := range []struct {
+		content      string
+		line, column int
+	}{
+		{storePolicyA + `{"name":"typo","users":["bob"],"actions":["*"]` + "\n", 2, 46},
+		{storePolicyA + "\n" + `{"name":"typo","users":"bob"}` + "\n", 3, 28},
+		{storePolicyA + `{"name":"v","minApiVersion":"1.x"}` + "\n", 2, 0},
+		{storePolicyA + `{"name":"c","classes":["root"]}` + "\n", 2, 0},
+	} {
+		if err := ioutil.WriteFile(p, []byte(c.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := f.LoadPolicies()
+		perr, ok := err.(*PolicyError)
+		if !ok {
+			t.Fatalf("%q: expected a PolicyError, got %v", c.content, err)
+		}
+		if perr.File != p || perr.Line != c.line || perr.Column != c.column {
+			t.Errorf("%q: error at %d:%d, expected %d:%d: %v", c.content, perr.Line, perr.Column, c.line, c.column, err)
+		}
+		if d := f.Decide("alice", "container_start"); !d.Allowed() || d.Policy != "a" {
+			t.Errorf("%q: previous policies not kept: %+v", c.content, d)
+		}
+		status := f.Status()
+		if status.Hash != hash || status.Policies != 1 || status.LastError != err.Error() || status.FailedAt == nil {
+			t.Errorf("%q: unexpected status %+v", c.content, status)
+		}
+	}
+
+	if err := ioutil.WriteFile(p, []byte(storePolicyB), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	if status := f.Status(); status.Hash == hash || status.Policies != 2 || status.LastError != "" || status.FailedAt != nil {
+		t.Errorf("status not cleared after successful reload: %+v", status)
+	}
+}
+
+func TestConcurrentDecideAndReload(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	f := NewAuthorizer(&Config{PolicyPath: p})
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := &authorization.Request{User: "alice", RequestMethod: "POST", RequestURI: "/containers/abc/start"}
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				d := f.DecideRequest(req)
+				if d.Allowed() != (d.Policy == "a") {
+					t.Errorf("decision from mixed snapshots: %+v", d)
+					return
+				}
+				if len(f.GetPolicies()) == 0 {
+					t.Error("empty snapshot observed")
+					return
+				}
+				f.Decide("bob", "container_list")
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		content := storePolicyA
+		if i%2 == 0 {
+			content = storePolicyB
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.LoadPolicies(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// TestWatchSymlinkSwap swaps the policy file the way kubernetes updates
+// ConfigMap volumes and expects the watcher to reload it
+func TestWatchSymlinkSwap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authz-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range map[string]string{"v1": storePolicyA, "v2": storePolicyB} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name, "policy.json"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("v1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(dir, "policy.json")
+	if err := os.Symlink("..data/policy.json", p); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewAuthorizer(&Config{PolicyPath: p}).(*authorizer)
+	if err := f.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	go f.watchPolicies()
+	time.Sleep(100 * time.Millisecond)
+
+	if err := os.Symlink("v2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if d := f.Decide("alice", "container_start"); d.Policy == "b" {
+			return
+		}
+	}
+	t.Fatal("policy not reloaded after symlink swap")
+}
+
+func TestPollPoliciesStopsOnClose(t *testing.T) {
+	f := NewAuthorizer(&Config{PolicyPath: writePolicyFile(t, storePolicyA)}).(*authorizer)
+	reloads := make(chan struct{}, 1)
+	stopped := make(chan struct{})
+	go func() {
+		f.pollPolicies(time.Millisecond, func() {
+			select {
+			case reloads <- struct{}{}:
+			default:
+			}
+		})
+		close(stopped)
+	}()
+	<-reloads
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("polling did not stop after close")
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("second close failed: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy test suites of requests and their expected decisions
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/authorization"
+	"gopkg.in/yaml.v2"
+)
+
+// PolicySuite is a yaml file of requests and the decisions policies must make
+// for them
+type PolicySuite struct {
+	Policy    string        `yaml:"policy"`    // Policy is the policy file, relative to the suite, empty for the configured one
+	PolicyDir string        `yaml:"policyDir"` // PolicyDir is the policy directory, relative to the suite
+	Cases     []*PolicyCase `yaml:"cases"`     // Cases are the requests to decide
+
+	path string
+}
+
+// PolicyCase is a request and its expected decision, the expected action,
+// policy and reason are only checked if set
+type PolicyCase struct {
+	Name    string            `yaml:"name"`    // Name describes the case, defaults to user, method and uri
+	User    string            `yaml:"user"`    // User is the requesting user
+	Method  string            `yaml:"method"`  // Method is the http method, GET if empty
+	URI     string            `yaml:"uri"`     // URI is the request uri
+	Body    string            `yaml:"body"`    // Body is the optional request body
+	Headers map[string]string `yaml:"headers"` // Headers are the optional request headers
+	Expect  Effect            `yaml:"expect"`  // Expect is allow or deny
+	Action  string            `yaml:"action"`  // Action is the expected resolved action
+	Policy  string            `yaml:"policy"`  // Policy is the expected applied policy
+	Reason  ReasonCode        `yaml:"reason"`  // Reason is the expected reason code
+}
+
+// CaseResult is the outcome of a test case
+type CaseResult struct {
+	Case     *PolicyCase
+	Decision *Decision
+	Failures []string // Failures describe every expectation the decision did not meet
+}
+
+// Passed reports whether the decision met all expectations
+func (r *CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// LoadPolicySuite reads and checks a test suite file
+func LoadPolicySuite(path string) (*PolicySuite, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	suite := &PolicySuite{path: path}
+	if err := yaml.UnmarshalStrict(data, suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test suite %s: %v", path, err)
+	}
+	for i, c := range suite.Cases {
+		if c.URI == "" {
+			return nil, fmt.Errorf("%s: case %d has no uri", path, i+1)
+		}
+		if c.Expect != EffectAllow && c.Expect != EffectDeny {
+			return nil, fmt.Errorf("%s: case %d expects %q, must be allow or deny", path, i+1, c.Expect)
+		}
+		if c.Method == "" {
+			c.Method = "GET"
+		}
+		c.Method = strings.ToUpper(c.Method)
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s %s %s", c.User, c.Method, c.URI)
+		}
+	}
+	return suite, nil
+}
+
+// Config returns config with the policy paths of the suite, if it sets any
+func (s *PolicySuite) Config(config Config) *Config {
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(s.path), p)
+	}
+	if s.Policy != "" || s.PolicyDir != "" {
+		config.PolicyPath, config.PolicyDir = resolve(s.Policy), resolve(s.PolicyDir)
+	}
+	return &config
+}
+
+// Run decides every case with the authorizer, as the plugin requests of
+// isulad are decided
+func (s *PolicySuite) Run(authorizer Authorizer) []*CaseResult {
+	results := make([]*CaseResult, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		req := &authorization.Request{
+			User:           c.User,
+			RequestMethod:  c.Method,
+			RequestURI:     c.URI,
+			RequestHeaders: c.Headers,
+		}
+		if c.Body != "" {
+			req.RequestBody = []byte(c.Body)
+		}
+		d := authorizer.DecideRequest(req)
+		r := &CaseResult{Case: c, Decision: d}
+		if d.Effect != c.Expect {
+			r.Failures = append(r.Failures, fmt.Sprintf("expected %s, got %s: %s", c.Expect, d.Effect, d.Message))
+		}
+		if c.Action != "" && d.Action != c.Action {
+			r.Failures = append(r.Failures, fmt.Sprintf("expected action %s, got %s", c.Action, d.Action))
+		}
+		if c.Policy != "" && d.Policy != c.Policy {
+			r.Failures = append(r.Failures, fmt.Sprintf("expected policy %s, got %q", c.Policy, d.Policy))
+		}
+		if c.Reason != "" && d.Reason != c.Reason {
+			r.Failures = append(r.Failures, fmt.Sprintf("expected reason %s, got %s", c.Reason, d.Reason))
+		}
+		results = append(results, r)
+	}
+	return results
+}
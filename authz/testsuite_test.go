@@ -0,0 +1,123 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy test suite tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+)
+
+func TestRunPolicySuite(t *testing.T) {
+	dir := writePolicyTree(t, map[string]string{
+		"policy.yaml": "version: 1\npolicies:\n  - name: viewers\n    users: [bob]\n    classes: [read]\n",
+		"tests/viewers.yaml": `policy: ../policy.yaml
+cases:
+  - name: bob reads logs
+    user: bob
+    uri: /v1.40/containers/abc/logs
+    expect: allow
+    action: container_logs
+    policy: viewers
+  - user: bob
+    method: post
+    uri: /containers/abc/exec
+    expect: deny
+    reason: not_granted
+  - user: bob
+    method: post
+    uri: /containers/abc/start
+    expect: allow
+    reason: readonly
+`,
+	})
+	suite, err := LoadPolicySuite(filepath.Join(dir, "tests/viewers.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorizer := NewAuthorizer(suite.Config(Config{PolicyPath: "/nonexistent"}))
+	if err := authorizer.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	results := suite.Run(authorizer)
+	if len(results) != 3 {
+		t.Fatalf("%d results for 3 cases", len(results))
+	}
+	if !results[0].Passed() || !results[1].Passed() {
+		t.Errorf("passing cases failed: %v %v", results[0].Failures, results[1].Failures)
+	}
+	if results[1].Case.Name != "bob POST /containers/abc/exec" {
+		t.Errorf("unexpected default case name %q", results[1].Case.Name)
+	}
+	if len(results[2].Failures) != 2 {
+		t.Errorf("expected effect and reason failures, got %v", results[2].Failures)
+	}
+}
+
+// requestRecorder records the requests a suite decides
+type requestRecorder struct {
+	Authorizer
+	requests []*authorization.Request
+}
+
+func (r *requestRecorder) DecideRequest(req *authorization.Request) *Decision {
+	r.requests = append(r.requests, req)
+	return r.Authorizer.DecideRequest(req)
+}
+
+func TestPolicySuiteRequestBody(t *testing.T) {
+	dir := writePolicyTree(t, map[string]string{
+		"policy.json": `{"name":"a","users":["bob"],"actions":["container_create"]}` + "\n",
+		"suite.yaml": `policy: policy.json
+cases:
+  - user: bob
+    method: post
+    uri: /containers/create
+    body: '{"Image":"busybox"}'
+    headers:
+      Content-Type: application/json
+    expect: allow
+`,
+	})
+	suite, err := LoadPolicySuite(filepath.Join(dir, "suite.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorizer := NewAuthorizer(suite.Config(Config{}))
+	if err := authorizer.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	rec := &requestRecorder{Authorizer: authorizer}
+	if results := suite.Run(rec); len(results) != 1 || !results[0].Passed() {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	req := rec.requests[0]
+	if string(req.RequestBody) != `{"Image":"busybox"}` || req.RequestHeaders["Content-Type"] != "application/json" {
+		t.Errorf("body or headers not passed on: %+v", req)
+	}
+}
+
+func TestLoadPolicySuiteErrors(t *testing.T) {
+	for _, suite := range []string{
+		"cases:\n  - user: bob\n    expect: allow\n",
+		"cases:\n  - user: bob\n    uri: /containers/json\n    expect: maybe\n",
+		"cases:\n  - user: bob\n    uri: /containers/json\n    expect: allow\n    expected: deny\n",
+	} {
+		dir := writePolicyTree(t, map[string]string{"suite.yaml": suite})
+		if _, err := LoadPolicySuite(filepath.Join(dir, "suite.yaml")); err == nil {
+			t.Errorf("%q: expected an error", suite)
+		}
+	}
+}
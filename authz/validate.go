@@ -0,0 +1,187 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy validation shared by the daemon and the validate command
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ValidationReport collects the errors and warnings found while loading
+// policies, policies with errors are never loaded
+type ValidationReport struct {
+	Policies int            `json:"policies"` // Policies is the number of policies parsed
+	Files    []string       `json:"files"`    // Files are the policy and routes files read, in load order
+	Errors   []*PolicyError `json:"errors"`   // Errors are the problems rejecting the policies
+	Warnings []*PolicyError `json:"warnings"` // Warnings are the problems the policies load with
+
+	hash string // hash is the sha256 of the files read
+}
+
+func (r *ValidationReport) errorf(pos position, name string, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, policyErrorAt(pos, name, format, args...))
+}
+
+func (r *ValidationReport) warnf(pos position, name string, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, policyErrorAt(pos, name, format, args...))
+}
+
+// Err returns the first error of the report, or nil if there is none
+func (r *ValidationReport) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return r.Errors[0]
+}
+
+// String summarizes the report
+func (r *ValidationReport) String() string {
+	return fmt.Sprintf("%d policies, %d errors, %d warnings", r.Policies, len(r.Errors), len(r.Warnings))
+}
+
+// ValidatePolicies parses and validates the policy and routes files of the
+// configuration the same way the daemon loads them
+func ValidatePolicies(config *Config) *ValidationReport {
+	_, report := loadPolicies(config)
+	return report
+}
+
+// loadPolicies parses and validates the configured files and builds an
+// engine from them, the engine is nil if the report has errors. The policy
+// file is loaded first, then the files of the policy directory in lexical
+// order.
+func loadPolicies(config *Config) (*engine, *ValidationReport) {
+	report := &ValidationReport{}
+	l := newPolicyLoader(report)
+	defer func() { report.hash = l.sum() }()
+
+	rt := newRouter(routes)
+	if config.RoutesPath != "" {
+		data, err := l.read(config.RoutesPath)
+		if err != nil {
+			report.Errors = append(report.Errors, &PolicyError{Err: err})
+			return nil, report
+		}
+		tables, err := parseRoutesFile(config.RoutesPath, data)
+		if err != nil {
+			report.Errors = append(report.Errors, &PolicyError{Err: err})
+			return nil, report
+		}
+		rt = newRouter(tables)
+	}
+
+	if config.PolicyPath != "" {
+		l.loadFile(config.PolicyPath)
+	}
+	if config.PolicyDir != "" {
+		l.loadDir(config.PolicyDir)
+	}
+	report.Policies = len(l.policies)
+	validatePolicies(rt, l.policies, config.LegacyActions, report)
+	if len(report.Errors) > 0 {
+		return nil, report
+	}
+	return newEngine(config, rt, l.policies), report
+}
+
+// validatePolicies checks policies against each other and the route table
+func validatePolicies(rt *router, policies []Policy, legacy bool, report *ValidationReport) {
+	names := make(map[string]position)
+	users := make(map[string]string)
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Name == "" {
+			report.errorf(policy.pos, "", "policy has no name")
+		} else if pos, ok := names[policy.Name]; ok {
+			report.errorf(policy.pos, policy.Name, "duplicate policy name, first defined at %s", pos)
+		} else {
+			names[policy.Name] = policy.pos
+		}
+
+		for _, u := range policy.Users {
+			if v, ok := users[u]; ok {
+				report.warnf(policy.pos, policy.Name, "user %q already appears in policy %q, only single policy applies", u, v)
+				continue
+			}
+			users[u] = policy.Name
+		}
+		versionsValid := true
+		for _, v := range []string{policy.MinAPIVersion, policy.MaxAPIVersion} {
+			if v == "" {
+				continue
+			}
+			if err := ParseAPIVersion(v); err != nil {
+				report.errorf(policy.pos, policy.Name, "%v", err)
+				versionsValid = false
+			}
+		}
+		if versionsValid && policy.MinAPIVersion != "" && policy.MaxAPIVersion != "" &&
+			compareAPIVersions(policy.MinAPIVersion, policy.MaxAPIVersion) > 0 {
+			report.errorf(policy.pos, policy.Name, "min api version %s is higher than max api version %s",
+				policy.MinAPIVersion, policy.MaxAPIVersion)
+		}
+		for _, c := range policy.Classes {
+			if !validClass(c) {
+				report.errorf(policy.pos, policy.Name, "invalid class %q", c)
+			}
+		}
+		for _, a := range policy.Actions {
+			validateActionEntry(rt, policy, a, legacy, report)
+		}
+	}
+}
+
+// validateActionEntry checks that an action entry compiles and that exact
+// entries name an action of the route table. With legacy action regexes such
+// entries only warn, as earlier policy files used names no route maps to.
+func validateActionEntry(rt *router, policy *Policy, entry string, legacy bool, report *ValidationReport) {
+	if entry == "" {
+		report.warnf(policy.pos, policy.Name, "empty action entry is deprecated, use %q to match every action", matchAllEntry)
+		return
+	}
+	re, err := compileActionEntry(entry, legacy)
+	if err != nil {
+		report.errorf(policy.pos, policy.Name, "invalid action entry %q: %v", entry, err)
+		return
+	}
+	if re == nil {
+		_, name := parseActionEntry(entry, legacy)
+		if _, ok := rt.actions[name]; !ok && name != UnknownAction {
+			if legacy {
+				report.warnf(policy.pos, policy.Name, "unknown action %q, no route maps to it", name)
+			} else {
+				report.errorf(policy.pos, policy.Name, "unknown action %q, no route maps to it", name)
+			}
+		}
+		return
+	}
+	if isUnanchoredEntry(entry, legacy) {
+		report.warnf(policy.pos, policy.Name,
+			"action pattern %q is unanchored and matches every action containing it, use an exact:, glob: or regex: entry instead", entry)
+	}
+}
+
+// logReport logs the warnings and errors of a report
+func logReport(report *ValidationReport) {
+	for _, w := range report.Warnings {
+		logrus.Warn(w.Error())
+	}
+	for _, e := range report.Errors {
+		logrus.Error(e.Error())
+	}
+	if n := len(report.Errors); n > 1 {
+		logrus.Errorf("%d errors in policies", n)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy validation tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidatePolicies(t *testing.T) {
+	cases := []struct {
+		policy   string
+		legacy   bool
+		errors   []string
+		warnings []string
+	}{
+		{policy: `{"name":"a","users":["alice"],"actions":["*"]}`},
+		{
+			policy: `{"name":"a","users":["alice"],"actons":["*"]}`,
+			errors: []string{`:1:31: json: unknown field "actons"`},
+		},
+		{
+			policy: `{"users":["alice"],"actions":["*"]}`,
+			errors: []string{":1: policy has no name"},
+		},
+		{
+			policy: `{"name":"a","users":["alice"],"actions":["*"]}` + "\n" + `{"name":"a","users":["bob"],"actions":["*"]}`,
+			errors: []string{":2: [policy: a] duplicate policy name, first defined at "},
+		},
+		{
+			policy: `{"name":"a","users":["alice"],"actions":["regex:container_(start"]}`,
+			errors: []string{`:1: [policy: a] invalid action entry "regex:container_(start"`},
+		},
+		{
+			policy: `{"name":"a","users":["alice"],"actions":["image_list"],"minApiVersion":"1.40","maxApiVersion":"1.24"}`,
+			errors: []string{`:1: [policy: a] min api version 1.40 is higher than max api version 1.24`},
+		},
+		{
+			policy: `{"name":"a","users":["alice"],"actions":["container_exec","exact:image_pull"]}`,
+			errors: []string{`:1: [policy: a] unknown action "container_exec"`, `:1: [policy: a] unknown action "image_pull"`},
+		},
+		{
+			policy:   `{"name":"a","users":["alice"],"actions":["exact:container_exec","exact:image_pull"]}`,
+			legacy:   true,
+			warnings: []string{`:1: [policy: a] unknown action "container_exec"`, `:1: [policy: a] unknown action "image_pull"`},
+		},
+		{
+			policy: `{"name":"a","users":["alice"],"actions":["glob:nothing_*","unknown",""]}` + "\n" +
+				`{"name":"b","users":["alice"],"actions":["image_list"]}`,
+			warnings: []string{":1: [policy: a] empty action entry", `:2: [policy: b] user "alice" already appears in policy "a"`},
+		},
+	}
+	for _, c := range cases {
+		p := writePolicyFile(t, c.policy+"\n")
+		report := ValidatePolicies(&Config{PolicyPath: p, LegacyActions: c.legacy})
+		check := func(kind string, got []*PolicyError, want []string) {
+			if len(got) != len(want) {
+				t.Errorf("%s: %d %s, want %d: %v", c.policy, len(got), kind, len(want), got)
+				return
+			}
+			for i, w := range want {
+				if msg := got[i].Error(); !strings.HasPrefix(msg, p) || !strings.Contains(msg, w) {
+					t.Errorf("%s: %s %q does not contain %q", c.policy, kind, msg, w)
+				}
+			}
+		}
+		check("errors", report.Errors, c.errors)
+		check("warnings", report.Warnings, c.warnings)
+	}
+}
+
+func TestValidateSharedWithLoad(t *testing.T) {
+	p := writePolicyFile(t, `{"name":"a","users":["alice"],"actions":["regex:container_(exec"]}`+"\n")
+	report := ValidatePolicies(&Config{PolicyPath: p})
+	err := NewAuthorizer(&Config{PolicyPath: p}).LoadPolicies()
+	if err == nil || fmt.Sprint(err) != fmt.Sprint(report.Err()) {
+		t.Fatalf("load error %v differs from validation error %v", err, report.Err())
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: watch policy files with inotify and reload them on change
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// policyReloadDebounce is how long to wait for more changes before reloading
+	policyReloadDebounce = 500 * time.Millisecond
+	// maxWatchReadErrors is how many reads in a row may fail before the
+	// watcher gives up, the read delay doubles after every failure
+	maxWatchReadErrors = 8
+
+	watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ATTRIB |
+		syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+)
+
+// policyWatcher watches the directories of the policy files rather than the
+// files themselves, so editors replacing a file by rename and symlink swaps
+// as done for kubernetes ConfigMaps are seen as well
+type policyWatcher struct {
+	fd    int
+	file  *os.File        // file reads the events of fd, closing it ends a pending read
+	paths func() []string // paths returns the files to watch, they change with drop-ins and includes
+
+	mu     sync.Mutex
+	dirs   map[string]int32 // dirs maps the watched directories to their watch descriptors
+	failed map[string]bool  // failed are the directories that could not be watched, warned once
+}
+
+func newPolicyWatcher(paths func() []string) (*policyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &policyWatcher{
+		fd:     fd,
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		paths:  paths,
+		dirs:   make(map[string]int32),
+		failed: make(map[string]bool),
+	}
+	w.addWatches()
+	if len(w.dirs) == 0 {
+		w.file.Close()
+		return nil, fmt.Errorf("no policy directory could be watched")
+	}
+	return w, nil
+}
+
+// addWatches watches the directory of every path and of the file it resolves
+// to, the latter changes whenever a symlink is swapped. Paths that are
+// directories themselves are watched too. Directories already watched are
+// skipped, those failing to be watched are tried again next time.
+func (w *policyWatcher) addWatches() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, p := range w.paths() {
+		dirs := []string{filepath.Dir(p)}
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			dirs = append(dirs, p)
+		}
+		if real, err := filepath.EvalSymlinks(p); err == nil {
+			dirs = append(dirs, filepath.Dir(real))
+		}
+		for _, dir := range dirs {
+			if _, ok := w.dirs[dir]; ok {
+				continue
+			}
+			wd, err := syscall.InotifyAddWatch(w.fd, dir, watchMask)
+			if err != nil {
+				if !w.failed[dir] {
+					logrus.Warnf("Failed to watch policy directory %s: %v", dir, err)
+					w.failed[dir] = true
+				}
+				continue
+			}
+			delete(w.failed, dir)
+			w.dirs[dir] = int32(wd)
+		}
+	}
+}
+
+// forget drops the directories whose watches the kernel removed, because
+// they were deleted or unmounted, so they are watched again once they exist
+func (w *policyWatcher) forget(events []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for off := 0; off+syscall.SizeofInotifyEvent <= len(events); {
+		ev := (*syscall.InotifyEvent)(unsafe.Pointer(&events[off]))
+		if ev.Mask&syscall.IN_IGNORED != 0 {
+			for dir, wd := range w.dirs {
+				if wd == ev.Wd {
+					delete(w.dirs, dir)
+				}
+			}
+		}
+		off += syscall.SizeofInotifyEvent + int(ev.Len)
+	}
+}
+
+// read sends a value to events whenever watched directories changed, until
+// the watcher is closed. Failing reads are retried with a doubling delay, it
+// gives up after maxWatchReadErrors failures in a row and returns the last error.
+func (w *policyWatcher) read(events chan<- struct{}) error {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	delay := 100 * time.Millisecond
+	for failures := 0; ; {
+		n, err := w.file.Read(buf)
+		if errors.Is(err, os.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			failures++
+			if failures >= maxWatchReadErrors {
+				return err
+			}
+			logrus.Errorf("Failed to read policy file events, retrying in %s: %v", delay, err)
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
+		failures, delay = 0, 100*time.Millisecond
+		if n > 0 {
+			w.forget(buf[:n])
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// run calls reload once changes settled for policyReloadDebounce until done
+// is closed, or returns the error of reading events
+func (w *policyWatcher) run(reload func(), done <-chan struct{}) error {
+	defer w.file.Close()
+	events := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- w.read(events)
+	}()
+
+	timer := time.NewTimer(policyReloadDebounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-events:
+			timer.Reset(policyReloadDebounce)
+		case <-timer.C:
+			// watch replaced directories before reading them, so changes
+			// while reloading are seen, and the directories of new drop-ins
+			// and includes after
+			w.addWatches()
+			reload()
+			w.addWatches()
+		case err := <-errs:
+			return err
+		case <-done:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy file watcher tests
+// Author: agent
+// Create: 2026-10-19
+
+package authz
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcherSkipsFailingDirectories(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	missing := filepath.Join(filepath.Dir(p), "missing", "policy.d")
+	w, err := newPolicyWatcher(func() []string { return []string{p, missing} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.file.Close()
+	if _, ok := w.dirs[filepath.Dir(p)]; !ok {
+		t.Errorf("policy directory not watched: %v", w.dirs)
+	}
+	if !w.failed[filepath.Dir(missing)] {
+		t.Errorf("missing directory not reported: %v", w.failed)
+	}
+
+	if _, err := newPolicyWatcher(func() []string { return []string{missing} }); err == nil {
+		t.Error("watcher without any watched directory created")
+	}
+}
+
+func TestWatcherAddsOnlyNewDirectories(t *testing.T) {
+	p := writePolicyFile(t, storePolicyA)
+	q := writePolicyFile(t, storePolicyB)
+	paths := []string{p}
+	w, err := newPolicyWatcher(func() []string { return paths })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.file.Close()
+	wd := w.dirs[filepath.Dir(p)]
+
+	paths = append(paths, q)
+	w.addWatches()
+	if len(w.dirs) != 2 || w.dirs[filepath.Dir(p)] != wd {
+		t.Errorf("unexpected watches after adding a path: %v", w.dirs)
+	}
+}
+
+func TestWatcherRewatchesReplacedDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authz-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "policy.d")
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newPolicyWatcher(func() []string { return []string{sub} })
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloads := make(chan struct{}, 16)
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- w.run(func() { reloads <- struct{}{} }, done)
+	}()
+	waitReload := func(what string) {
+		select {
+		case <-reloads:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no reload after %s", what)
+		}
+	}
+
+	if err := os.RemoveAll(sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	waitReload("replacing the directory")
+	if err := ioutil.WriteFile(filepath.Join(sub, "10-a.json"), []byte(storePolicyA), 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitReload("writing to the replaced directory")
+
+	close(done)
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher did not stop")
+	}
+}
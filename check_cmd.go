@@ -0,0 +1,158 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: check subcommand simulating the decision for a request offline
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/pkg/authorization"
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var checkCommand = cli.Command{
+	Name:  "check",
+	Usage: "Decide a request against the policies offline, exits 1 if it is denied",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "user",
+			Usage: "Specify the requesting user",
+		},
+		cli.StringFlag{
+			Name:  "method",
+			Value: "GET",
+			Usage: "Specify the http method of the request",
+		},
+		cli.StringFlag{
+			Name:  "uri",
+			Usage: "Specify the request uri, e.g. /v1.40/containers/abc/logs",
+		},
+		cli.StringFlag{
+			Name:  "body",
+			Usage: "Specify a file with the request body, - for stdin",
+		},
+		cli.StringSliceFlag{
+			Name:  "header",
+			Usage: "Add a request header as 'Name: value', may be repeated",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: "Specify the output format (text or json)",
+		},
+	},
+	Action: checkRequest,
+}
+
+// newOfflineAuthorizer loads the policies of the global flags without
+// starting the server, only warnings are logged
+func newOfflineAuthorizer(c *cli.Context) (authz.Authorizer, error) {
+	quietLogs()
+	config, err := newOfflineConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	authorizer := authz.NewAuthorizer(config)
+	if err := authorizer.LoadPolicies(); err != nil {
+		return nil, err
+	}
+	return authorizer, nil
+}
+
+// newCheckRequest builds the plugin request isulad would send
+func newCheckRequest(c *cli.Context) (*authorization.Request, error) {
+	if c.String("uri") == "" {
+		return nil, fmt.Errorf("--uri is required")
+	}
+	req := &authorization.Request{
+		User:           c.String("user"),
+		RequestMethod:  strings.ToUpper(c.String("method")),
+		RequestURI:     c.String("uri"),
+		RequestHeaders: make(map[string]string),
+	}
+	for _, h := range c.StringSlice("header") {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid header %q, must be 'Name: value'", h)
+		}
+		req.RequestHeaders[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	switch body := c.String("body"); body {
+	case "":
+	case "-":
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		req.RequestBody = data
+	default:
+		data, err := ioutil.ReadFile(body)
+		if err != nil {
+			return nil, err
+		}
+		req.RequestBody = data
+	}
+	return req, nil
+}
+
+func checkRequest(c *cli.Context) error {
+	req, err := newCheckRequest(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	authorizer, err := newOfflineAuthorizer(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	decision := authorizer.DecideRequest(req)
+
+	switch c.String("format") {
+	case "text":
+		fmt.Fprintf(os.Stdout, "action:   %s\n", decision.Action)
+		if decision.Class != "" {
+			fmt.Fprintf(os.Stdout, "class:    %s\n", decision.Class)
+		}
+		if decision.Resource != "" {
+			fmt.Fprintf(os.Stdout, "resource: %s\n", decision.Resource)
+		}
+		if decision.APIVersion != "" {
+			fmt.Fprintf(os.Stdout, "version:  %s\n", decision.APIVersion)
+		}
+		fmt.Fprintf(os.Stdout, "decision: %s\n", decision.Effect)
+		if decision.Policy != "" {
+			fmt.Fprintf(os.Stdout, "policy:   %s (%s)\n", decision.Policy, decision.Source)
+		}
+		if decision.Rule != "" {
+			fmt.Fprintf(os.Stdout, "rule:     %s\n", decision.Rule)
+		}
+		fmt.Fprintf(os.Stdout, "reason:   %s\n", decision.Reason)
+		fmt.Fprintf(os.Stdout, "message:  %s\n", decision.Message)
+	case "json":
+		data, err := json.MarshalIndent(decision, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 2)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q, must be text or json", c.String("format")), 2)
+	}
+	if !decision.Allowed() {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: check subcommand tests
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+const checkPolicy = `{"name":"ops","users":["alice"],"actions":["container_logs"]}` + "\n"
+
+func TestCheckText(t *testing.T) {
+	p := writeFile(t, "policy.json", checkPolicy)
+	out, code := runApp(t, "--policy-file", p, "check", "--user", "alice", "--uri", "/v1.40/containers/web/logs")
+	if code != 0 {
+		t.Errorf("exit code %d, want 0", code)
+	}
+	for _, line := range []string{
+		"action:   container_logs\n",
+		"class:    read\n",
+		"resource: web\n",
+		"version:  1.40\n",
+		"decision: allow\n",
+		"policy:   ops (" + p + ":1)\n",
+		"rule:     container_logs\n",
+		"reason:   granted\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("output misses %q:\n%s", line, out)
+		}
+	}
+
+	out, code = runApp(t, "--policy-file", p, "check", "--user", "alice", "--method", "post", "--uri", "/containers/web/start")
+	if code != 1 || !strings.Contains(out, "decision: deny\n") || !strings.Contains(out, "reason:   not_granted\n") ||
+		strings.Contains(out, "version:") {
+		t.Errorf("denied request exited %d:\n%s", code, out)
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	p := writeFile(t, "policy.json", checkPolicy)
+	out, code := runApp(t, "--policy-file", p, "check", "--user", "bob", "--uri", "/v1.41/info", "--format", "json")
+	if code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	var d authz.Decision
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("invalid json %v:\n%s", err, out)
+	}
+	if d.Action != "isulad_info" || d.APIVersion != "1.41" || d.Effect != authz.EffectDeny || d.Reason != authz.ReasonNoPolicy {
+		t.Errorf("unexpected decision %+v", d)
+	}
+}
+
+// TestCheckRoutesFile checks the action and version are resolved by the
+// routes of the loaded policies, not the builtin ones
+func TestCheckRoutesFile(t *testing.T) {
+	p := writeFile(t, "policy.json", `{"name":"ops","users":["alice"],"actions":["container_checkpoint"]}`+"\n")
+	routes := filepath.Join(filepath.Dir(p), "routes.yaml")
+	data := "routes:\n- method: POST\n  path: /containers/{name}/checkpoint\n  action: container_checkpoint\n  class: write\n  minApiVersion: \"1.30\"\n"
+	if err := ioutil.WriteFile(routes, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out, code := runApp(t, "--policy-file", p, "--routes-file", routes, "check",
+		"--user", "alice", "--method", "POST", "--uri", "/v1.40/containers/web/checkpoint", "--format", "json")
+	var d authz.Decision
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("invalid json %v:\n%s", err, out)
+	}
+	if code != 0 || d.Action != "container_checkpoint" || d.APIVersion != "1.40" || !d.Allowed() {
+		t.Errorf("exit code %d, unexpected decision %+v", code, d)
+	}
+
+	_, code = runApp(t, "--policy-file", p, "--routes-file", routes, "--unversioned-api", "1.20", "check",
+		"--user", "alice", "--method", "POST", "--uri", "/containers/web/checkpoint")
+	if code != 1 {
+		t.Errorf("route below its min version allowed, exit code %d", code)
+	}
+}
+
+func TestCheckUsage(t *testing.T) {
+	p := writeFile(t, "policy.json", checkPolicy)
+	cases := [][]string{
+		{"--policy-file", p, "check", "--user", "alice"},
+		{"--policy-file", p, "check", "--uri", "/info", "--format", "xml"},
+		{"--policy-file", writeFile(t, "bad.json", `{"name":`), "check", "--uri", "/info"},
+	}
+	for _, args := range cases {
+		if _, code := runApp(t, args...); code != 2 {
+			t.Errorf("%v: exit code %d, want 2", args, code)
+		}
+	}
+}
+
+func TestNewCheckRequest(t *testing.T) {
+	body := writeFile(t, "body.json", `{"Cmd":["sh"]}`)
+	set := flag.NewFlagSet("check", flag.ContinueOnError)
+	for _, f := range checkCommand.Flags {
+		f.Apply(set)
+	}
+	args := []string{"--user", "alice", "--method", "post", "--uri", "/v1.40/containers/web/exec",
+		"--body", body, "--header", "Content-Type: application/json", "--header", "X-Trace:a:b"}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	req, err := newCheckRequest(cli.NewContext(nil, set, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"Content-Type": "application/json", "X-Trace": "a:b"}
+	if req.RequestMethod != "POST" || string(req.RequestBody) != `{"Cmd":["sh"]}` || !reflect.DeepEqual(req.RequestHeaders, want) {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	p := writeFile(t, "policy.json", checkPolicy)
+	cases := [][]string{
+		{"--policy-file", p, "check", "--uri", "/info", "--header", "X-Trace"},
+		{"--policy-file", p, "check", "--uri", "/info", "--body", filepath.Join(filepath.Dir(p), "missing.json")},
+	}
+	for _, args := range cases {
+		if _, code := runApp(t, args...); code != 2 {
+			t.Errorf("%v: exit code %d, want 2", args, code)
+		}
+	}
+}
+
+// TestOfflineIgnoresLearning checks learning mode of the global flags does
+// not allow the requests offline commands decide
+func TestOfflineIgnoresLearning(t *testing.T) {
+	p := writeFile(t, "policy.json", checkPolicy)
+	learning := filepath.Join(filepath.Dir(p), "learning.log")
+	out, code := runApp(t, "--policy-file", p, "--learning-log", learning, "check",
+		"--user", "alice", "--method", "POST", "--uri", "/containers/web/start")
+	if code != 1 || !strings.Contains(out, "reason:   not_granted\n") {
+		t.Errorf("check in learning mode exited %d:\n%s", code, out)
+	}
+
+	suite := writeFile(t, "suite.yaml", "policy: "+p+"\ncases:\n  - user: alice\n    method: post\n    uri: /containers/web/start\n    expect: deny\n")
+	if out, code := runApp(t, "--policy-file", p, "--learning-log", learning, "policy", "test", suite); code != 0 {
+		t.Errorf("policy test in learning mode exited %d:\n%s", code, out)
+	}
+}
@@ -17,12 +17,12 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/plugins"
 	"github.com/sirupsen/logrus"
+	"isula.org/authz/authz"
 )
 
 // HandleFunc handle function for authz
@@ -60,12 +60,12 @@ func (a *AuthZServer) HandleRequest() HandleFunc {
 			return
 		}
 
-		resp := a.authorizer.AuthZRequest(req)
+		resp, decision := a.authorizer.AuthZRequest(req)
 		if resp != nil {
-			logrus.Debugf(resp.Msg)
+			logrus.Debug(resp.Msg)
 		}
 
-		err = a.auditor.AuditRequest(req, resp)
+		err = a.auditor.AuditRequest(req, resp, decision)
 		if err != nil {
 			logrus.Errorf("Failed to audit request '%v'", err)
 		}
@@ -137,7 +137,12 @@ func (a *AuthZServer) HandleIsuladRequest() HandleFunc {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-		items := strings.Split(string(body), ":")
+		if isJSONRequest(r, body) {
+			a.handleIsuladJSONRequest(w, body)
+			return
+		}
+		// the user name must not contain a colon, the action may
+		items := strings.SplitN(string(body), ":", 2)
 		if len(items) != 2 { // Standard format length
 			logrus.Errorf("Bad format: %v", items)
 			w.WriteHeader(http.StatusBadRequest)
@@ -150,54 +155,128 @@ func (a *AuthZServer) HandleIsuladRequest() HandleFunc {
 	}
 }
 
-// AuthIsuladUser authorize user for isulad http request
-func (a *AuthZServer) AuthIsuladUser(username string, action string) int {
-	if err := a.authorizer.LoadPolicies(); err != nil {
-		logrus.Errorf("Failed to load policies: %s", err)
+func isJSONRequest(r *http.Request, body []byte) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") ||
+		strings.HasPrefix(strings.TrimSpace(string(body)), "{")
+}
+
+// handleIsuladJSONRequest handle isulad authz request in json format
+func (a *AuthZServer) handleIsuladJSONRequest(w http.ResponseWriter, body []byte) {
+	req, err := authz.ParseIsuladAuthRequest(body)
+	if err != nil {
+		logrus.Errorf("Bad isulad.auth request: %v", err)
+		resp := &authz.IsuladAuthResponse{Version: authz.IsuladAuthVersion, Err: err.Error()}
+		if req != nil {
+			resp.RequestID = req.RequestID
+		}
+		writeIsuladResponse(w, http.StatusBadRequest, resp)
+		return
+	}
+
+	decision := a.authorizer.Decide(req.User, req.Action)
+	logrus.Debugf("isulad.auth request %s: user '%s' action '%s' resource '%s' attributes %v auth method '%s': %s",
+		req.RequestID, req.User, req.Action, req.Resource, req.Attributes, req.AuthMethod, decision.Message)
+
+	writeIsuladResponse(w, isuladStatusCode(decision), authz.NewIsuladAuthResponse(req, decision))
+}
+
+// isuladStatusCode returns the http status of an isulad.auth decision, users
+// without policy get 404 and invalid rules 500 as before the decision engine
+func isuladStatusCode(decision *authz.Decision) int {
+	switch {
+	case decision.Allowed():
+		return http.StatusOK
+	case decision.Reason == authz.ReasonNoPolicy:
+		return http.StatusNotFound
+	case decision.Reason == authz.ReasonInvalidRule:
 		return http.StatusInternalServerError
 	}
-	for _, policy := range a.authorizer.GetPolicies() {
-		for _, user := range policy.Users {
-			if user == "" || user == username {
-				for _, policyActionPattern := range policy.Actions {
-					match, err := regexp.MatchString(policyActionPattern, action)
-					if err != nil {
-						logrus.Errorf(
-							"Failed to recognize action %q against policy %q error %q",
-							action,
-							policyActionPattern,
-							err.Error(),
-						)
-						return http.StatusInternalServerError
-					}
-
-					if match {
-						if policy.Readonly {
-							logrus.Errorf(
-								"action '%s' not allowed for user '%s' by readonly policy %s",
-								action,
-								username,
-								policy.Name,
-							)
-							return http.StatusForbidden
-						}
-						return http.StatusOK
-					}
-				}
-				logrus.Errorf(
-					"action '%s' denied for user '%s' by policy '%s'",
-					action,
-					username,
-					policy.Name,
-				)
-				return http.StatusForbidden
+	return http.StatusForbidden
+}
+
+// HandleIsuladBatchRequest handle isulad authz requests of many user/action pairs
+func (a *AuthZServer) HandleIsuladBatchRequest() HandleFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if r.Body != nil {
+				r.Body.Close()
 			}
+		}()
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			logrus.Errorf("Failed to read body from request: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
+
+		req, err := authz.ParseIsuladBatchRequest(body)
+		if err != nil {
+			logrus.Errorf("Bad isulad.auth.batch request: %v", err)
+			resp := &authz.IsuladBatchResponse{Version: authz.IsuladAuthVersion, Err: err.Error()}
+			if req != nil {
+				resp.RequestID = req.RequestID
+			}
+			writeIsuladResponse(w, http.StatusBadRequest, resp)
+			return
+		}
+
+		// only valid checks are decided, invalid ones get their error
+		checkErrs := req.CheckErrors()
+		checks := make([]authz.ActionCheck, 0, len(req.Checks))
+		for i, check := range req.Checks {
+			if checkErrs[i] == nil {
+				checks = append(checks, authz.ActionCheck{User: check.User, Action: check.Action})
+			}
+		}
+		decisions := a.authorizer.DecideBatch(checks)
+
+		resp := &authz.IsuladBatchResponse{
+			Version:   authz.IsuladAuthVersion,
+			RequestID: req.RequestID,
+			Results:   make([]*authz.IsuladAuthResponse, 0, len(req.Checks)),
+		}
+		for i, checkErr := range checkErrs {
+			if checkErr != nil {
+				resp.Results = append(resp.Results, &authz.IsuladAuthResponse{
+					Version:   authz.IsuladAuthVersion,
+					RequestID: req.Checks[i].RequestID,
+					Err:       checkErr.Error(),
+				})
+				continue
+			}
+			resp.Results = append(resp.Results, authz.NewIsuladAuthResponse(req.Checks[i], decisions[0]))
+			decisions = decisions[1:]
+		}
+		writeIsuladResponse(w, http.StatusOK, resp)
+	}
+}
+
+// HandlePolicyStatus reports the active policies and the last failed reload
+func (a *AuthZServer) HandlePolicyStatus() HandleFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeIsuladResponse(w, http.StatusOK, a.authorizer.Status())
+	}
+}
+
+func writeIsuladResponse(w http.ResponseWriter, code int, resp interface{}) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		logrus.Errorf("Failed to marshal isulad.auth response %q", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if _, err := w.Write(data); err != nil {
+		logrus.Warnf("write http response err:%v", err)
+	}
+}
+
+// AuthIsuladUser authorize user for isulad http request
+func (a *AuthZServer) AuthIsuladUser(username string, action string) int {
+	decision := a.authorizer.Decide(username, action)
+	if !decision.Allowed() {
+		logrus.Error(decision.Message)
 	}
-	logrus.Errorf(
-		"no policy applied (user: '%s' action: '%s')",
-		username,
-		action,
-	)
-	return http.StatusNotFound
+	return isuladStatusCode(decision)
 }
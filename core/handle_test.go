@@ -0,0 +1,367 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: conformance tests of the plugin and isulad.auth handlers
+// Author: agent
+// Create: 2026-10-19
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/authorization"
+	"isula.org/authz/authz"
+)
+
+const conformancePolicy = `{"name":"admins","users":["alice"],"actions":["*"]}
+{"name":"viewers","users":["bob"],"actions":["container_*"],"readonly":true}
+{"name":"operators","users":["carol"],"actions":["regex:container_exec","container_list"],"classes":["read"]}
+`
+
+type nopAuditor struct{}
+
+func (nopAuditor) AuditRequest(req *authorization.Request, resp *authorization.Response, decision *authz.Decision) error {
+	return nil
+}
+
+func (nopAuditor) AuditResponse(req *authorization.Request, resp *authorization.Response) error {
+	return nil
+}
+
+type conformanceCase struct {
+	user   string
+	method string
+	uri    string
+	action string
+	allow  bool
+}
+
+var conformanceCases = []conformanceCase{
+	{"alice", "POST", "/containers/abc/exec", "container_exec_create", true},
+	{"alice", "DELETE", "/images/busybox", "image_delete", true},
+	{"bob", "GET", "/containers/abc/logs", "container_logs", true},
+	{"bob", "HEAD", "/containers/abc/archive", "container_archive_info", true},
+	{"bob", "GET", "/containers/abc/export", "container_export", false},
+	{"bob", "GET", "/containers/abc/archive", "container_archive", false},
+	{"bob", "POST", "/containers/abc/start", "container_start", false},
+	{"bob", "GET", "/images/json", "image_list", false},
+	{"carol", "POST", "/containers/abc/exec", "container_exec_create", false},
+	{"carol", "GET", "/containers/json", "container_list", true},
+	{"carol", "GET", "/images/json", "image_list", true},
+	{"carol", "POST", "/containers/abc/kill", "container_kill", false},
+	{"carol", "POST", "/exec/123/start", "container_exec_start", false},
+	{"erin", "GET", "/containers/json", "container_list", false},
+}
+
+func newConformanceServer(t *testing.T) *AuthZServer {
+	dir, err := ioutil.TempDir("", "authz-conformance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	policyPath := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(policyPath, []byte(conformancePolicy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	authorizer := authz.NewAuthorizer(&authz.Config{PolicyPath: policyPath})
+	if err := authorizer.LoadPolicies(); err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthZServer(authorizer, nopAuditor{})
+}
+
+func TestConformancePluginRequest(t *testing.T) {
+	srv := newConformanceServer(t)
+	for _, c := range conformanceCases {
+		body, err := json.Marshal(&authorization.Request{User: c.user, RequestMethod: c.method, RequestURI: c.uri})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		srv.HandleRequest()(w, httptest.NewRequest("POST", "/AuthZPlugin.AuthZReq", bytes.NewReader(body)))
+		resp := &authorization.Response{}
+		if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Allow != c.allow {
+			t.Errorf("%s %s %s: allow = %v, want %v (%s)", c.user, c.method, c.uri, resp.Allow, c.allow, resp.Msg)
+		}
+	}
+}
+
+func TestConformanceIsuladRequest(t *testing.T) {
+	srv := newConformanceServer(t)
+	for _, c := range conformanceCases {
+		w := httptest.NewRecorder()
+		body := bytes.NewBufferString(c.user + ":" + c.action)
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", body))
+		if allow := w.Code == http.StatusOK; allow != c.allow {
+			t.Errorf("%s %s: status = %d, want allow %v", c.user, c.action, w.Code, c.allow)
+		}
+	}
+}
+
+func TestConformanceIsuladJSONRequest(t *testing.T) {
+	srv := newConformanceServer(t)
+	for _, c := range conformanceCases {
+		body, err := json.Marshal(&authz.IsuladAuthRequest{
+			Version:   authz.IsuladAuthVersion,
+			RequestID: "req-" + c.user,
+			User:      c.user,
+			Action:    c.action,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewReader(body)))
+		resp := &authz.IsuladAuthResponse{}
+		if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Allow != c.allow || (w.Code == http.StatusOK) != c.allow {
+			t.Errorf("%s %s: allow = %v status = %d, want allow %v (%s)", c.user, c.action, resp.Allow, w.Code, c.allow, resp.Msg)
+		}
+		if resp.RequestID != "req-"+c.user {
+			t.Errorf("%s %s: request id = %q", c.user, c.action, resp.RequestID)
+		}
+	}
+}
+
+func TestIsuladJSONRequestBadVersion(t *testing.T) {
+	srv := newConformanceServer(t)
+	w := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"version":"0","user":"a:b","action":"container_list"}`)
+	srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", body))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestConformanceIsuladBatchRequest(t *testing.T) {
+	srv := newConformanceServer(t)
+	req := &authz.IsuladBatchRequest{Version: authz.IsuladAuthVersion, RequestID: "batch"}
+	for _, c := range conformanceCases {
+		req.Checks = append(req.Checks, &authz.IsuladAuthRequest{User: c.user, Action: c.action})
+	}
+	req.Checks = append(req.Checks, &authz.IsuladAuthRequest{User: "alice"})
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	srv.HandleIsuladBatchRequest()(w, httptest.NewRequest("POST", "/isulad.auth.batch", bytes.NewReader(body)))
+	resp := &authz.IsuladBatchResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(resp.Results) != len(req.Checks) {
+		t.Fatalf("status = %d, %d results for %d checks (%s)", w.Code, len(resp.Results), len(req.Checks), resp.Err)
+	}
+	for i, c := range conformanceCases {
+		if resp.Results[i].Allow != c.allow {
+			t.Errorf("%s %s: allow = %v, want %v (%s)", c.user, c.action, resp.Results[i].Allow, c.allow, resp.Results[i].Msg)
+		}
+	}
+	if last := resp.Results[len(resp.Results)-1]; last.Allow || last.Err == "" {
+		t.Errorf("check without action: allow = %v err = %q", last.Allow, last.Err)
+	}
+}
+
+func TestIsuladRequestStatusCodes(t *testing.T) {
+	srv := newConformanceServer(t)
+	cases := []struct {
+		user   string
+		action string
+		code   int
+	}{
+		{"alice", "container_list", http.StatusOK},
+		{"bob", "container_start", http.StatusForbidden},
+		{"erin", "container_list", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewBufferString(c.user+":"+c.action)))
+		if w.Code != c.code {
+			t.Errorf("%s:%s: status = %d, want %d", c.user, c.action, w.Code, c.code)
+		}
+
+		body, err := json.Marshal(&authz.IsuladAuthRequest{Version: authz.IsuladAuthVersion, User: c.user, Action: c.action})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = httptest.NewRecorder()
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewReader(body)))
+		if w.Code != c.code {
+			t.Errorf("%s %s json: status = %d, want %d", c.user, c.action, w.Code, c.code)
+		}
+	}
+}
+
+func TestIsuladRequestPlainFormat(t *testing.T) {
+	srv := newConformanceServer(t)
+	cases := []struct {
+		body string
+		code int
+	}{
+		{"alice:container_list", http.StatusOK},
+		{"alice:container_list:extra", http.StatusOK},
+		{"bob:container_start:extra", http.StatusForbidden},
+		{"alice", http.StatusBadRequest},
+		{"", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewBufferString(c.body)))
+		if w.Code != c.code {
+			t.Errorf("%q: status = %d, want %d", c.body, w.Code, c.code)
+		}
+	}
+}
+
+// TestConformanceStrictValidation checks policies rejected by the strict
+// validation are never served, the previous policies stay in effect
+func TestConformanceStrictValidation(t *testing.T) {
+	cases := []string{
+		`{"name":"admins","users":["alice"],"actons":["glob:*"]}`,
+		`{"users":["alice"],"actions":["glob:*"]}`,
+		`{"name":"admins","users":["alice"],"actions":["glob:*"]}` + "\n" + `{"name":"admins","users":["bob"],"actions":["glob:*"]}`,
+		`{"name":"admins","users":["alice"],"actions":["regex:container_(start"]}`,
+		`{"name":"admins","users":["alice"],"actions":["glob:*"],"classes":["root"]}`,
+	}
+	for _, policy := range cases {
+		dir, err := ioutil.TempDir("", "authz-conformance")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		policyPath := filepath.Join(dir, "policy.json")
+		if err := ioutil.WriteFile(policyPath, []byte(conformancePolicy), 0600); err != nil {
+			t.Fatal(err)
+		}
+		authorizer := authz.NewAuthorizer(&authz.Config{PolicyPath: policyPath})
+		if err := authorizer.LoadPolicies(); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(policyPath, []byte(policy+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := authorizer.LoadPolicies(); err == nil {
+			t.Errorf("%s: loaded", policy)
+		}
+
+		srv := NewAuthZServer(authorizer, nopAuditor{})
+		w := httptest.NewRecorder()
+		srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewBufferString("bob:container_start")))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: previous policies not kept, status = %d", policy, w.Code)
+		}
+	}
+}
+
+// TestLearningModeEndpoints checks learning mode allows and records the
+// requests of the plugin, isulad.auth and batch endpoints alike
+func TestLearningModeEndpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authz-learning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	policyPath := filepath.Join(dir, "policy.json")
+	if err := ioutil.WriteFile(policyPath, []byte(conformancePolicy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(dir, "learning.log")
+	authorizer := authz.NewAuthorizer(&authz.Config{PolicyPath: policyPath, LearningPath: logPath})
+	if err := authorizer.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer authorizer.Close()
+	srv := NewAuthZServer(authorizer, nopAuditor{})
+
+	body, err := json.Marshal(&authorization.Request{User: "bob", RequestMethod: "POST", RequestURI: "/containers/abc/start"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	srv.HandleRequest()(w, httptest.NewRequest("POST", "/AuthZPlugin.AuthZReq", bytes.NewReader(body)))
+	resp := &authorization.Response{}
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil || !resp.Allow {
+		t.Errorf("plugin request not allowed: %s %v", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewBufferString("erin:container_list")))
+	if w.Code != http.StatusOK {
+		t.Errorf("isulad.auth request of a user without policy: status = %d", w.Code)
+	}
+
+	body, err = json.Marshal(&authz.IsuladAuthRequest{Version: authz.IsuladAuthVersion, User: "carol", Action: "container_kill"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("isulad.auth json request: status = %d", w.Code)
+	}
+
+	body, err = json.Marshal(&authz.IsuladBatchRequest{
+		Version: authz.IsuladAuthVersion,
+		Checks:  []*authz.IsuladAuthRequest{{User: "dave", Action: "image_push"}, {User: "bob"}, {User: "alice", Action: "image_list"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	srv.HandleIsuladBatchRequest()(w, httptest.NewRequest("POST", "/isulad.auth.batch", bytes.NewReader(body)))
+	batch := &authz.IsuladBatchResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Results) != 3 || batch.Results[1].Allow || batch.Results[1].Err == "" {
+		t.Fatalf("unexpected batch results %+v", batch.Results)
+	}
+	for _, r := range []*authz.IsuladAuthResponse{batch.Results[0], batch.Results[2]} {
+		if !r.Allow {
+			t.Errorf("batch check not allowed: %+v", r)
+		}
+	}
+
+	// requests without action are never learned
+	w = httptest.NewRecorder()
+	srv.HandleIsuladRequest()(w, httptest.NewRequest("POST", "/isulad.auth", bytes.NewBufferString("bob:")))
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	requests, err := authz.ReadLearningLog(f, logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	learned := make(map[string]bool)
+	for _, r := range requests {
+		learned[r.User+" "+r.Action] = true
+	}
+	for _, want := range []string{"bob container_start", "erin container_list", "carol container_kill", "dave image_push", "alice image_list"} {
+		if !learned[want] {
+			t.Errorf("%s not recorded, learned %v", want, learned)
+		}
+	}
+}
@@ -83,11 +83,16 @@ func (a *AuthZServer) route() error {
 	router.HandleFunc(fmt.Sprintf("/%s", authorization.AuthZApiRequest), a.HandleRequest())
 	router.HandleFunc(fmt.Sprintf("/%s", authorization.AuthZApiResponse), a.HandleResponse())
 	router.HandleFunc("/isulad.auth", a.HandleIsuladRequest())
+	router.HandleFunc("/isulad.auth.batch", a.HandleIsuladBatchRequest())
+	router.HandleFunc("/isulad.auth.status", a.HandlePolicyStatus())
 	return http.Serve(a.listener, router)
 }
 
 // Stop stop the authorization server
 func (a *AuthZServer) Stop() error {
+	if err := a.authorizer.Close(); err != nil {
+		logrus.Errorf("Failed to close authorizer err: %s", err.Error())
+	}
 	if a.listener == nil {
 		return fmt.Errorf("Listener is nil")
 	}
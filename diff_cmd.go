@@ -0,0 +1,88 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy diff subcommand comparing the decisions of two policy sets
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var policyDiffCommand = cli.Command{
+	Name:      "diff",
+	Usage:     "Show which users gain or lose which actions between two policy sets, exits 1 if any do",
+	ArgsUsage: "old-policy new-policy",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: "Specify the output format (text or json)",
+		},
+	},
+	Action: diffPolicies,
+}
+
+// policyMatrix expands the policy file or directory at p with the settings
+// of the global flags
+func policyMatrix(config authz.Config, p string) (*authz.PermissionMatrix, error) {
+	config.PolicyPath, config.PolicyDir = p, ""
+	if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+		config.PolicyPath, config.PolicyDir = "", p
+	}
+	return authz.NewPermissionMatrix(&config)
+}
+
+func diffPolicies(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return cli.NewExitError("policy diff needs the old and the new policy", 2)
+	}
+	config, err := newOfflineConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	old, err := policyMatrix(*config, c.Args().Get(0))
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	updated, err := policyMatrix(*config, c.Args().Get(1))
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	changes := authz.DiffPermissions(old, updated)
+
+	switch c.String("format") {
+	case "text":
+		for _, line := range authz.SummarizeChanges(changes) {
+			fmt.Fprintln(os.Stdout, line)
+		}
+	case "json":
+		if changes == nil {
+			changes = []*authz.PermissionChange{}
+		}
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 2)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q, must be text or json", c.String("format")), 2)
+	}
+	if len(changes) > 0 {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
@@ -24,4 +24,5 @@ require (
 	github.com/sirupsen/logrus v1.4.2
 	github.com/urfave/cli v1.21.0
 	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
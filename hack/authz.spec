@@ -56,7 +56,7 @@ fi
 chmod 0750 /var/lib/authz-broker
 if [ ! -f "/var/lib/authz-broker/policy.json" ]; then
 	cat > /var/lib/authz-broker/policy.json << EOF
-{"name":"policy_root","users":[""],"actions":[""]}
+{"name":"policy_root","users":[""],"actions":["glob:*"]}
 EOF
 fi
 chmod 0640 /var/lib/authz-broker/policy.json
@@ -0,0 +1,102 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy learn subcommand proposing policies from a learning log
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var policyLearnCommand = cli.Command{
+	Name:      "learn",
+	Usage:     "Propose the policies allowing exactly the requests recorded in learning mode",
+	ArgsUsage: "learning-log... (- for stdin)",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: authz.FormatYAML,
+			Usage: "Specify the output format (jsonl, yaml or json)",
+		},
+		cli.BoolFlag{
+			Name:  "group-users",
+			Usage: "Let users allowed the same actions share a policy",
+		},
+		cli.IntFlag{
+			Name:  "glob",
+			Usage: "Replace the actions of a prefix such as container_ by a glob once a user did that many of them, 0 never",
+		},
+		cli.BoolFlag{
+			Name:  "resources",
+			Usage: "Print the resources of each user and action to stderr, with ids and numbers generalized into patterns",
+		},
+	},
+	Action: learnPolicies,
+}
+
+// readLearningLogs reads the learned requests of all files, - is stdin
+func readLearningLogs(paths []string) ([]*authz.LearnedRequest, error) {
+	var requests []*authz.LearnedRequest
+	for _, p := range paths {
+		var r io.Reader = os.Stdin
+		if p != "-" {
+			f, err := os.Open(p)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		rs, err := authz.ReadLearningLog(r, p)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, rs...)
+	}
+	return requests, nil
+}
+
+func learnPolicies(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("policy learn needs at least one learning log", 2)
+	}
+	if c.Int("glob") < 0 {
+		return cli.NewExitError("--glob must not be negative", 2)
+	}
+	requests, err := readLearningLogs(c.Args())
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	policies := authz.LearnPolicies(requests, authz.LearnOptions{
+		GroupUsers: c.Bool("group-users"),
+		GlobMin:    c.Int("glob"),
+	})
+	data, err := authz.EncodePolicies(policies, c.String("format"))
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		return err
+	}
+	if c.Bool("resources") {
+		// policies cannot restrict resources, they are printed for review only
+		for _, line := range authz.SummarizeResources(requests) {
+			fmt.Fprintf(os.Stderr, "# %s\n", line)
+		}
+	}
+	return nil
+}
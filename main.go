@@ -28,8 +28,19 @@ import (
 )
 
 const (
-	debugFlag      = "debug"
-	policyFileFlag = "policy-file"
+	debugFlag         = "debug"
+	policyFileFlag    = "policy-file"
+	policyDirFlag     = "policy-dir"
+	routesFileFlag    = "routes-file"
+	unknownActionFlag = "unknown-action"
+	legacyActionsFlag = "legacy-action-regex"
+	minAPIVersionFlag = "min-api-version"
+	maxAPIVersionFlag = "max-api-version"
+	unversionedFlag   = "unversioned-api"
+	shadowPolicyFlag  = "shadow-policy-file"
+	shadowDirFlag     = "shadow-policy-dir"
+	shadowAuditFlag   = "shadow-audit-log"
+	learningFlag      = "learning-log"
 )
 
 var (
@@ -37,8 +48,50 @@ var (
 	pidFile = "/run/authz.pid"
 )
 
-func main() {
+// newConfig builds the authorizer configuration from the global flags
+func newConfig(c *cli.Context) (*authz.Config, error) {
+	unknownAction, err := authz.ParseUnknownActionMode(c.GlobalString(unknownActionFlag))
+	if err != nil {
+		return nil, err
+	}
+
+	config := &authz.Config{
+		PolicyPath:     c.GlobalString(policyFileFlag),
+		PolicyDir:      c.GlobalString(policyDirFlag),
+		RoutesPath:     c.GlobalString(routesFileFlag),
+		UnknownAction:  unknownAction,
+		LegacyActions:  c.GlobalBool(legacyActionsFlag),
+		MinAPIVersion:  c.GlobalString(minAPIVersionFlag),
+		MaxAPIVersion:  c.GlobalString(maxAPIVersionFlag),
+		UnversionedAPI: c.GlobalString(unversionedFlag),
+
+		ShadowPolicyPath: c.GlobalString(shadowPolicyFlag),
+		ShadowPolicyDir:  c.GlobalString(shadowDirFlag),
+		ShadowAuditPath:  c.GlobalString(shadowAuditFlag),
+
+		LearningPath: c.GlobalString(learningFlag),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// newOfflineConfig builds the configuration of the commands deciding requests
+// without the daemon, learning mode and shadow policies only apply to the
+// daemon
+func newOfflineConfig(c *cli.Context) (*authz.Config, error) {
+	config, err := newConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	config.ShadowPolicyPath, config.ShadowPolicyDir, config.ShadowAuditPath = "", "", ""
+	config.LearningPath = ""
+	return config, nil
+}
 
+// newApp builds the command line application, the root action runs the daemon
+func newApp() *cli.App {
 	app := cli.NewApp()
 	app.Name = "authz-broker"
 	app.Usage = "Authorization plugin for isulad"
@@ -56,6 +109,11 @@ func main() {
 			logrus.SetLevel(logrus.InfoLevel)
 		}
 
+		config, err := newConfig(c)
+		if err != nil {
+			panic(err)
+		}
+
 		// init authz pid file
 		file, err := pidfile.New(pidFile)
 		if err != nil {
@@ -71,7 +129,7 @@ func main() {
 		}()
 
 		// start authz server
-		authorizer := authz.NewAuthorizer(c.GlobalString(policyFileFlag))
+		authorizer := authz.NewAuthorizer(config)
 		auditor := authz.NewAuditor()
 		srv := core.NewAuthZServer(authorizer, auditor)
 		go func() {
@@ -86,6 +144,8 @@ func main() {
 		srv.Stop()
 	}
 
+	app.Commands = []cli.Command{policyCommand, checkCommand, replayCommand}
+
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{
 			Name:   debugFlag,
@@ -96,9 +156,69 @@ func main() {
 			Name:   policyFileFlag,
 			Value:  "/var/lib/authz-broker/policy.json",
 			EnvVar: "AUTHZ-POLICY-FILE",
-			Usage:  "Specify authz policy file",
+			Usage:  "Specify authz policy file, in json lines or as yaml or json policy document",
+		},
+		cli.StringFlag{
+			Name:   policyDirFlag,
+			EnvVar: "AUTHZ-POLICY-DIR",
+			Usage:  "Specify a drop-in directory whose policy files are loaded after the policy file in lexical order",
+		},
+		cli.StringFlag{
+			Name:   routesFileFlag,
+			EnvVar: "AUTHZ-ROUTES-FILE",
+			Usage:  "Specify a yaml or json file adding, overriding or disabling routes",
+		},
+		cli.StringFlag{
+			Name:   unknownActionFlag,
+			Value:  string(authz.UnknownActionDeny),
+			EnvVar: "AUTHZ-UNKNOWN-ACTION",
+			Usage:  "Specify how requests to unknown routes are handled (deny, allow or audit)",
+		},
+		cli.BoolFlag{
+			Name:   legacyActionsFlag,
+			EnvVar: "AUTHZ-LEGACY-ACTION-REGEX",
+			Usage:  "Match policy actions without exact:, glob: or regex: prefix as unanchored regexes",
+		},
+		cli.StringFlag{
+			Name:   minAPIVersionFlag,
+			EnvVar: "AUTHZ-MIN-API-VERSION",
+			Usage:  "Specify the lowest api version allowed",
+		},
+		cli.StringFlag{
+			Name:   maxAPIVersionFlag,
+			EnvVar: "AUTHZ-MAX-API-VERSION",
+			Usage:  "Specify the highest api version allowed",
+		},
+		cli.StringFlag{
+			Name:   unversionedFlag,
+			Value:  authz.UnversionedAPIAllow,
+			EnvVar: "AUTHZ-UNVERSIONED-API",
+			Usage:  "Specify how requests without api version are handled (allow, deny or an api version to assume)",
+		},
+		cli.StringFlag{
+			Name:   shadowPolicyFlag,
+			EnvVar: "AUTHZ-SHADOW-POLICY-FILE",
+			Usage:  "Specify a candidate policy file decided alongside the enforcing one without affecting responses",
+		},
+		cli.StringFlag{
+			Name:   shadowDirFlag,
+			EnvVar: "AUTHZ-SHADOW-POLICY-DIR",
+			Usage:  "Specify a drop-in directory of candidate policies loaded after the shadow policy file",
+		},
+		cli.StringFlag{
+			Name:   shadowAuditFlag,
+			EnvVar: "AUTHZ-SHADOW-AUDIT-LOG",
+			Usage:  "Specify the file requests the shadow policies decide differently are audited to, syslog tag authz-shadow if empty",
+		},
+		cli.StringFlag{
+			Name:   learningFlag,
+			EnvVar: "AUTHZ-LEARNING-LOG",
+			Usage:  "Enable learning mode, allow every request and record the users, actions and resources seen to the file for policy learn",
 		},
 	}
+	return app
+}
 
-	app.Run(os.Args)
+func main() {
+	newApp().Run(os.Args)
 }
@@ -0,0 +1,178 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy matrix subcommand exporting effective permissions
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var policyMatrixCommand = cli.Command{
+	Name:      "matrix",
+	Usage:     "Export which user may do which action as table, csv, json or html",
+	ArgsUsage: "[policy-file|policy-dir]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "table",
+			Usage: "Specify the output format (table, csv, json or html)",
+		},
+	},
+	Action: exportMatrix,
+}
+
+func exportMatrix(c *cli.Context) error {
+	var write func(io.Writer, *authz.PermissionMatrix) error
+	switch c.String("format") {
+	case "table":
+		write = writeMatrixTable
+	case "csv":
+		write = writeMatrixCSV
+	case "json":
+		write = writeMatrixJSON
+	case "html":
+		write = writeMatrixHTML
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q, must be table, csv, json or html", c.String("format")), 2)
+	}
+	config, err := policyConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	m, err := authz.NewPermissionMatrix(config)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if err := write(os.Stdout, m); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+// cellText abbreviates a cell as yes or -, with readonly and conditions
+// annotated in parentheses
+func cellText(cell *authz.MatrixCell) string {
+	text := "-"
+	var notes []string
+	if cell.Allow {
+		text = "yes"
+		if cell.Readonly {
+			notes = append(notes, "ro")
+		}
+		notes = append(notes, cell.Conditions...)
+	} else if cell.Reason == authz.ReasonReadonly {
+		notes = append(notes, "ro")
+	}
+	if len(notes) > 0 {
+		text += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return text
+}
+
+// writeMatrixTable writes one row per action and one column per user
+func writeMatrixTable(w io.Writer, m *authz.PermissionMatrix) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "ACTION\tCLASS\t%s\n", strings.Join(m.Users, "\t"))
+	for a, action := range m.Actions {
+		cells := make([]string, len(m.Users))
+		for u := range m.Users {
+			cells[u] = cellText(m.Cells[u][a])
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", action.Name, action.Class, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeMatrixCSV writes one record per user and action
+func writeMatrixCSV(w io.Writer, m *authz.PermissionMatrix) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user", "action", "class", "allow", "readonly", "conditions", "policy", "rule", "reason", "source"})
+	for u, user := range m.Users {
+		for a, action := range m.Actions {
+			cell := m.Cells[u][a]
+			cw.Write([]string{
+				user,
+				action.Name,
+				action.Class,
+				strconv.FormatBool(cell.Allow),
+				strconv.FormatBool(cell.Readonly),
+				strings.Join(cell.Conditions, "; "),
+				cell.Policy,
+				cell.Rule,
+				string(cell.Reason),
+				cell.Source,
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeMatrixJSON(w io.Writer, m *authz.PermissionMatrix) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+var matrixTemplate = template.Must(template.New("matrix").Funcs(template.FuncMap{
+	"cell": func(m *authz.PermissionMatrix, u, a int) *authz.MatrixCell { return m.Cells[u][a] },
+	"text": cellText,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>authz-broker permission matrix</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; white-space: nowrap; }
+th { background: #eee; position: sticky; top: 0; }
+td.allow { background: #d4f4d4; }
+td.deny { color: #999; }
+</style>
+</head>
+<body>
+<h1>Permission matrix</h1>
+<p>{{len .Users}} users, {{len .Actions}} actions. ro: granted by a readonly policy. {{.AnyUser}}: any user not named in a policy.</p>
+<table>
+<tr><th>action</th><th>class</th>{{range .Matrix.Users}}<th>{{.}}</th>{{end}}</tr>
+{{range $a, $action := .Matrix.Actions}}<tr><td>{{$action.Name}}</td><td>{{$action.Class}}</td>{{range $u, $user := $.Matrix.Users}}{{with cell $.Matrix $u $a}}<td class="{{if .Allow}}allow{{else}}deny{{end}}" title="{{.Reason}}{{if .Policy}} by policy {{.Policy}}{{end}}{{if .Rule}} rule {{.Rule}}{{end}}{{if .Source}} ({{.Source}}){{end}}">{{text .}}</td>{{end}}{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeMatrixHTML writes a static html page of the table with the deciding
+// policy and rule as cell titles
+func writeMatrixHTML(w io.Writer, m *authz.PermissionMatrix) error {
+	return matrixTemplate.Execute(w, struct {
+		Matrix  *authz.PermissionMatrix
+		Users   []string
+		Actions []authz.MatrixAction
+		AnyUser string
+	}{m, m.Users, m.Actions, authz.AnyUser})
+}
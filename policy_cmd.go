@@ -0,0 +1,184 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy subcommands working on policy files offline
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var policyCommand = cli.Command{
+	Name:  "policy",
+	Usage: "Work with policy files without running the daemon",
+	Subcommands: []cli.Command{
+		{
+			Name:      "validate",
+			Usage:     "Validate a policy file the way the daemon loads it",
+			ArgsUsage: "[policy-file|policy-dir]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Specify the report format (text or json)",
+				},
+				cli.BoolFlag{
+					Name:  "strict",
+					Usage: "Fail on warnings as well",
+				},
+			},
+			Action: validatePolicy,
+		},
+		{
+			Name:      "lint",
+			Usage:     "Validate a policy file and warn about shadowed policies, wildcards and dangerous grants, exits 1 on any finding",
+			ArgsUsage: "[policy-file|policy-dir]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: "text",
+					Usage: "Specify the report format (text or json)",
+				},
+			},
+			Action: lintPolicy,
+		},
+		policyTestCommand,
+		policyMatrixCommand,
+		policyDiffCommand,
+		policyLearnCommand,
+		{
+			Name:      "convert",
+			Usage:     "Convert a policy file between json lines and yaml or json documents, comments are not kept",
+			ArgsUsage: "[policy-file]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format",
+					Value: authz.FormatYAML,
+					Usage: "Specify the output format (jsonl, yaml or json)",
+				},
+			},
+			Action: convertPolicy,
+		},
+	},
+}
+
+// quietLogs sends logs of offline commands to stderr and only logs warnings,
+// so their output stays machine readable
+func quietLogs() {
+	logrus.SetOutput(os.Stderr)
+	logrus.SetLevel(logrus.WarnLevel)
+}
+
+// policyConfig builds the configuration from the global flags, with the
+// policy file or directory optionally given as first argument
+func policyConfig(c *cli.Context) (*authz.Config, error) {
+	config, err := newOfflineConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.NArg() > 0 {
+		p := c.Args().First()
+		config.PolicyPath, config.PolicyDir = p, ""
+		if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+			config.PolicyPath, config.PolicyDir = "", p
+		}
+	}
+	return config, nil
+}
+
+// validateReport is the json report of policy validate
+type validateReport struct {
+	Valid bool `json:"valid"`
+	*authz.ValidationReport
+}
+
+func validatePolicy(c *cli.Context) error {
+	config, err := policyConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	return printReport(authz.ValidatePolicies(config), c.String("format"), c.Bool("strict"))
+}
+
+func lintPolicy(c *cli.Context) error {
+	config, err := policyConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	return printReport(authz.LintPolicies(config), c.String("format"), true)
+}
+
+// printReport prints a validation report in format, it fails if the report
+// has errors, or warnings in strict mode
+func printReport(report *authz.ValidationReport, format string, strict bool) error {
+	valid := len(report.Errors) == 0 && (!strict || len(report.Warnings) == 0)
+
+	switch format {
+	case "text":
+		printIssues(report.Errors, "error")
+		printIssues(report.Warnings, "warning")
+		fmt.Fprintf(os.Stdout, "%d files: %s\n", len(report.Files), report)
+	case "json":
+		if report.Errors == nil {
+			report.Errors = []*authz.PolicyError{}
+		}
+		if report.Warnings == nil {
+			report.Warnings = []*authz.PolicyError{}
+		}
+		data, err := json.MarshalIndent(validateReport{Valid: valid, ValidationReport: report}, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 2)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown report format %q, must be text or json", format), 2)
+	}
+	if !valid {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+// printIssues prints issues compiler style as file:line:column: severity: message
+func printIssues(issues []*authz.PolicyError, severity string) {
+	for _, e := range issues {
+		if loc := e.Location(); loc != "" {
+			fmt.Fprintf(os.Stdout, "%s: %s: %s\n", loc, severity, e.Message())
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s: %s\n", severity, e.Message())
+	}
+}
+
+func convertPolicy(c *cli.Context) error {
+	switch format := c.String("format"); format {
+	case authz.FormatJSONLines, authz.FormatYAML, authz.FormatJSON:
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q, must be jsonl, yaml or json", format), 2)
+	}
+	config, err := policyConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	data, err := authz.ConvertPolicyFile(config.PolicyPath, c.String("format"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
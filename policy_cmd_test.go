@@ -0,0 +1,204 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy subcommand tests
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+// runApp runs the command line with args and returns its stdout and exit code
+func runApp(t *testing.T, args ...string) (string, int) {
+	out, err := ioutil.TempFile("", "authz-stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	stdout, exiter, errWriter := os.Stdout, cli.OsExiter, cli.ErrWriter
+	os.Stdout, cli.OsExiter, cli.ErrWriter = out, func(int) {}, ioutil.Discard
+	err = newApp().Run(append([]string{"authz-broker"}, args...))
+	os.Stdout, cli.OsExiter, cli.ErrWriter = stdout, exiter, errWriter
+
+	code := 0
+	if err != nil {
+		code = 1
+		if e, ok := err.(cli.ExitCoder); ok {
+			code = e.ExitCode()
+		}
+	}
+	data, rerr := ioutil.ReadFile(out.Name())
+	if rerr != nil {
+		t.Fatal(rerr)
+	}
+	return string(data), code
+}
+
+// writeFile writes content to name in a new temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "authz-cmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	p := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+const (
+	validPolicy   = `{"name":"a","users":["alice"],"actions":["container_list"]}` + "\n"
+	warnPolicy    = `{"name":"a","users":["alice"],"actions":[""]}` + "\n"
+	legacyPolicy  = `{"name":"a","users":["alice"],"actions":["exact:container_exec"]}` + "\n"
+	invalidPolicy = `{"name":"a","users":["alice"],"actions":["regex:container_(list"]}` + "\n"
+)
+
+func TestPolicyValidateExitCodes(t *testing.T) {
+	valid := writeFile(t, "valid.json", validPolicy)
+	warn := writeFile(t, "warn.json", warnPolicy)
+	invalid := writeFile(t, "invalid.json", invalidPolicy)
+	legacy := writeFile(t, "legacy.json", legacyPolicy)
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"policy", "validate", valid}, 0},
+		{[]string{"--policy-file", valid, "policy", "validate"}, 0},
+		{[]string{"policy", "validate", filepath.Dir(valid)}, 0},
+		{[]string{"policy", "validate", warn}, 0},
+		{[]string{"policy", "validate", "--strict", warn}, 1},
+		{[]string{"policy", "validate", invalid}, 1},
+		{[]string{"policy", "validate", legacy}, 1},
+		{[]string{"--legacy-action-regex", "policy", "validate", legacy}, 0},
+		{[]string{"--legacy-action-regex", "policy", "validate", "--strict", legacy}, 1},
+		{[]string{"policy", "validate", filepath.Join(filepath.Dir(valid), "missing.json")}, 1},
+		{[]string{"policy", "validate", "--format", "xml", valid}, 2},
+		{[]string{"--unknown-action", "maybe", "policy", "validate", valid}, 2},
+		{[]string{"--min-api-version", "1.40", "--max-api-version", "1.24", "policy", "validate", valid}, 2},
+	}
+	for _, c := range cases {
+		if _, code := runApp(t, c.args...); code != c.code {
+			t.Errorf("%v: exit code %d, want %d", c.args, code, c.code)
+		}
+	}
+}
+
+func TestPolicyValidateText(t *testing.T) {
+	p := writeFile(t, "policy.json", validPolicy+
+		`{"name":"b","users":["bob"],"actions":[""]}`+"\n"+
+		`{"name":"c","users":["carol"],"actions":["regex:container_(list"]}`+"\n")
+	out, code := runApp(t, "policy", "validate", p)
+	if code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	want := []string{
+		p + ":3: error: [policy: c] invalid action entry",
+		p + ":2: warning: [policy: b] empty action entry is deprecated",
+		"1 files: 3 policies, 1 errors, 1 warnings",
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(lines[i], w) {
+			t.Errorf("line %d is %q, want prefix %q", i+1, lines[i], w)
+		}
+	}
+}
+
+func TestPolicyValidateJSON(t *testing.T) {
+	cases := []struct {
+		policy   string
+		strict   bool
+		valid    bool
+		errors   int
+		warnings int
+	}{
+		{validPolicy, false, true, 0, 0},
+		{warnPolicy, false, true, 0, 1},
+		{warnPolicy, true, false, 0, 1},
+		{invalidPolicy, false, false, 1, 0},
+	}
+	for _, c := range cases {
+		args := []string{"policy", "validate", "--format", "json"}
+		if c.strict {
+			args = append(args, "--strict")
+		}
+		p := writeFile(t, "policy.json", c.policy)
+		out, _ := runApp(t, append(args, p)...)
+		var report struct {
+			Valid    bool              `json:"valid"`
+			Policies int               `json:"policies"`
+			Files    []string          `json:"files"`
+			Errors   []json.RawMessage `json:"errors"`
+			Warnings []json.RawMessage `json:"warnings"`
+		}
+		if err := json.Unmarshal([]byte(out), &report); err != nil {
+			t.Fatalf("%s: invalid json report %v:\n%s", c.policy, err, out)
+		}
+		if report.Valid != c.valid || len(report.Errors) != c.errors || len(report.Warnings) != c.warnings ||
+			report.Errors == nil || report.Warnings == nil || len(report.Files) != 1 || report.Files[0] != p {
+			t.Errorf("%s (strict %t): unexpected report\n%s", c.policy, c.strict, out)
+		}
+	}
+}
+
+func TestPolicyConvert(t *testing.T) {
+	p := writeFile(t, "policy.json", validPolicy+`{"name":"b","users":[""],"classes":["read"],"readonly":true}`+"\n")
+	yamlOut, code := runApp(t, "policy", "convert", p)
+	if code != 0 || !strings.HasPrefix(yamlOut, "version: 1\npolicies:\n- name: a\n") {
+		t.Fatalf("yaml conversion failed with %d:\n%s", code, yamlOut)
+	}
+	jsonOut, code := runApp(t, "policy", "convert", "--format", "json", p)
+	var doc struct {
+		Version  int `json:"version"`
+		Policies []struct {
+			Name string `json:"name"`
+		} `json:"policies"`
+	}
+	if err := json.Unmarshal([]byte(jsonOut), &doc); code != 0 || err != nil || doc.Version != 1 || len(doc.Policies) != 2 {
+		t.Fatalf("json conversion failed with %d %v:\n%s", code, err, jsonOut)
+	}
+
+	// converting back gives the policies of the original file
+	y := writeFile(t, "policy.yaml", yamlOut)
+	lines, code := runApp(t, "policy", "convert", "--format", "jsonl", y)
+	if code != 0 {
+		t.Fatalf("jsonl conversion failed with %d", code)
+	}
+	orig, _ := runApp(t, "policy", "convert", "--format", "jsonl", p)
+	if lines != orig {
+		t.Errorf("round trip changed policies:\n%s\nwant\n%s", lines, orig)
+	}
+	if _, code := runApp(t, "policy", "validate", y); code != 0 {
+		t.Errorf("converted policy file does not validate")
+	}
+
+	if _, code := runApp(t, "policy", "convert", writeFile(t, "bad.json", `{"name":`)); code != 1 {
+		t.Errorf("broken policy file converted with exit code %d, want 1", code)
+	}
+	if _, code := runApp(t, "policy", "convert", "--format", "toml", p); code != 2 {
+		t.Errorf("unknown format exit code %d, want 2", code)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy test subcommand running test suites with tap or junit output
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var policyTestCommand = cli.Command{
+	Name:      "test",
+	Usage:     "Run yaml test suites of requests and expected decisions against the policies",
+	ArgsUsage: "suite.yaml [suite.yaml...]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "tap",
+			Usage: "Specify the summary format (tap or junit)",
+		},
+	},
+	Action: testPolicy,
+}
+
+// suiteResults are the results of one test suite file
+type suiteResults struct {
+	path    string
+	results []*authz.CaseResult
+	err     error // err is why the suite could not run
+}
+
+func testPolicy(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("no test suite given", 2)
+	}
+	var write func(io.Writer, []*suiteResults)
+	switch c.String("format") {
+	case "tap":
+		write = writeTAP
+	case "junit":
+		write = writeJUnit
+	default:
+		return cli.NewExitError(fmt.Sprintf("unknown summary format %q, must be tap or junit", c.String("format")), 2)
+	}
+	config, err := newOfflineConfig(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	quietLogs()
+
+	failed := false
+	var suites []*suiteResults
+	for _, path := range c.Args() {
+		sr := runSuite(path, config)
+		for _, r := range sr.results {
+			failed = failed || !r.Passed()
+		}
+		failed = failed || sr.err != nil
+		suites = append(suites, sr)
+	}
+	write(os.Stdout, suites)
+	if failed {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+func runSuite(path string, config *authz.Config) *suiteResults {
+	sr := &suiteResults{path: path}
+	suite, err := authz.LoadPolicySuite(path)
+	if err != nil {
+		sr.err = err
+		return sr
+	}
+	authorizer := authz.NewAuthorizer(suite.Config(*config))
+	if err := authorizer.LoadPolicies(); err != nil {
+		sr.err = err
+		return sr
+	}
+	sr.results = suite.Run(authorizer)
+	return sr
+}
+
+// writeTAP writes the results in the test anything protocol, version 13
+func writeTAP(w io.Writer, suites []*suiteResults) {
+	n := 0
+	for _, s := range suites {
+		n += len(s.results)
+		if s.err != nil {
+			n++
+		}
+	}
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", n)
+	i := 0
+	for _, s := range suites {
+		if s.err != nil {
+			i++
+			fmt.Fprintf(w, "not ok %d - %s\n", i, s.path)
+			writeTAPDiagnostics(w, []string{s.err.Error()})
+			continue
+		}
+		for _, r := range s.results {
+			i++
+			if r.Passed() {
+				fmt.Fprintf(w, "ok %d - %s: %s\n", i, s.path, r.Case.Name)
+				continue
+			}
+			fmt.Fprintf(w, "not ok %d - %s: %s\n", i, s.path, r.Case.Name)
+			writeTAPDiagnostics(w, r.Failures)
+		}
+	}
+}
+
+func writeTAPDiagnostics(w io.Writer, failures []string) {
+	fmt.Fprintln(w, "  ---")
+	fmt.Fprintln(w, "  failures:")
+	for _, f := range failures {
+		fmt.Fprintf(w, "    - %q\n", f)
+	}
+	fmt.Fprintln(w, "  ...")
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Error    *junitMessage   `xml:"error,omitempty"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the results as junit xml, one testsuite per file
+func writeJUnit(w io.Writer, suites []*suiteResults) {
+	out := junitTestSuites{}
+	for _, s := range suites {
+		js := junitTestSuite{Name: s.path, Tests: len(s.results)}
+		if s.err != nil {
+			js.Errors = 1
+			js.Error = &junitMessage{Message: s.err.Error()}
+		}
+		for _, r := range s.results {
+			jc := junitTestCase{Name: r.Case.Name, ClassName: s.path}
+			if !r.Passed() {
+				js.Failures++
+				jc.Failure = &junitMessage{Message: r.Failures[0], Text: strings.Join(r.Failures, "\n")}
+			}
+			js.Cases = append(js.Cases, jc)
+		}
+		out.Tests += js.Tests
+		out.Failures += js.Failures
+		out.Errors += js.Errors
+		out.Suites = append(out.Suites, js)
+	}
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write junit report: %v\n", err)
+	}
+	fmt.Fprintln(w)
+}
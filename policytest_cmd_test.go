@@ -0,0 +1,86 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: policy test subcommand tests
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"isula.org/authz/authz"
+)
+
+// testSuiteResults are a passing and a failing case of one suite and a suite
+// that failed to load
+func testSuiteResults() []*suiteResults {
+	return []*suiteResults{
+		{
+			path: "tests/viewers.yaml",
+			results: []*authz.CaseResult{
+				{Case: &authz.PolicyCase{Name: "bob reads logs"}},
+				{
+					Case:     &authz.PolicyCase{Name: "bob POST /containers/abc/start"},
+					Failures: []string{"expected allow, got deny: denied", `expected reason "readonly", got not_granted`},
+				},
+			},
+		},
+		{path: "tests/broken.yaml", err: errors.New("tests/broken.yaml: case 1 has no uri")},
+	}
+}
+
+const goldenTAP = `TAP version 13
+1..3
+ok 1 - tests/viewers.yaml: bob reads logs
+not ok 2 - tests/viewers.yaml: bob POST /containers/abc/start
+  ---
+  failures:
+    - "expected allow, got deny: denied"
+    - "expected reason \"readonly\", got not_granted"
+  ...
+not ok 3 - tests/broken.yaml
+  ---
+  failures:
+    - "tests/broken.yaml: case 1 has no uri"
+  ...
+`
+
+const goldenJUnit = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" errors="1">
+  <testsuite name="tests/viewers.yaml" tests="2" failures="1" errors="0">
+    <testcase name="bob reads logs" classname="tests/viewers.yaml"></testcase>
+    <testcase name="bob POST /containers/abc/start" classname="tests/viewers.yaml">
+      <failure message="expected allow, got deny: denied">expected allow, got deny: denied&#xA;expected reason &#34;readonly&#34;, got not_granted</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="tests/broken.yaml" tests="0" failures="0" errors="1">
+    <error message="tests/broken.yaml: case 1 has no uri"></error>
+  </testsuite>
+</testsuites>
+`
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	writeTAP(&buf, testSuiteResults())
+	if buf.String() != goldenTAP {
+		t.Errorf("unexpected tap output:\n%s\nwant\n%s", buf.String(), goldenTAP)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	writeJUnit(&buf, testSuiteResults())
+	if buf.String() != goldenJUnit {
+		t.Errorf("unexpected junit output:\n%s\nwant\n%s", buf.String(), goldenJUnit)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) Huawei Technologies Co., Ltd. 2026. All rights reserved.
+// authz is licensed under the Mulan PSL v2.
+// You can use this software according to the terms and conditions of the Mulan PSL v2.
+// You may obtain a copy of Mulan PSL v2 at:
+//    http://license.coscl.org.cn/MulanPSL2
+// THIS SOFTWARE IS PROVIDED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OF ANY KIND, EITHER EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO NON-INFRINGEMENT, MERCHANTABILITY OR FIT FOR A PARTICULAR
+// PURPOSE.
+// See the Mulan PSL v2 for more details.
+// Description: replay subcommand deciding audited requests with candidate policies
+// Author: agent
+// Create: 2026-10-19
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+	"isula.org/authz/authz"
+)
+
+var replayCommand = cli.Command{
+	Name:      "replay",
+	Usage:     "Decide audited requests with the policies offline and report the decisions that flip, exits 1 if any do",
+	ArgsUsage: "audit-log... (- for stdin)",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Value: "text",
+			Usage: "Specify the output format (text or json)",
+		},
+	},
+	Action: replayAuditLogs,
+}
+
+// replayReport is the json output of replay
+type replayReport struct {
+	Skipped int `json:"skipped"`
+	*authz.ReplayReport
+}
+
+// readAuditLogs reads the audit records of all files, - is stdin
+func readAuditLogs(paths []string) ([]*authz.AuditRecord, int, error) {
+	var records []*authz.AuditRecord
+	skipped := 0
+	for _, p := range paths {
+		var r io.Reader = os.Stdin
+		if p != "-" {
+			f, err := os.Open(p)
+			if err != nil {
+				return nil, 0, err
+			}
+			defer f.Close()
+			r = f
+		}
+		rs, n, err := authz.ReadAuditLog(r, p)
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, rs...)
+		skipped += n
+	}
+	return records, skipped, nil
+}
+
+func replayAuditLogs(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError("replay needs at least one audit log", 2)
+	}
+	format := c.String("format")
+	if format != "text" && format != "json" {
+		return cli.NewExitError(fmt.Sprintf("unknown output format %q, must be text or json", format), 2)
+	}
+	authorizer, err := newOfflineAuthorizer(c)
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	records, skipped, err := readAuditLogs(c.Args())
+	if err != nil {
+		return cli.NewExitError(err, 2)
+	}
+	report := authz.Replay(authorizer, records)
+
+	if format == "json" {
+		if report.Flips == nil {
+			report.Flips = []*authz.FlippedDecision{}
+			report.Counts = []*authz.FlipCount{}
+		}
+		data, err := json.MarshalIndent(replayReport{Skipped: skipped, ReplayReport: report}, "", "  ")
+		if err != nil {
+			return cli.NewExitError(err, 2)
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	} else {
+		for _, f := range report.Flips {
+			fmt.Fprintf(os.Stdout, "%s: %s %s %s: %s -> %s: %s\n", f.Record.Source, f.Record.User, f.Record.Method,
+				f.Record.URI, effectText(f.Record.Allow), f.Decision.Effect, f.Decision.Message)
+		}
+		for _, n := range report.Counts {
+			fmt.Fprintf(os.Stdout, "%7d %s %s %s\n", n.Count, n.User, n.Kind, n.Action)
+		}
+		fmt.Fprintf(os.Stdout, "%d records, %d skipped lines, %d decisions flip\n", report.Records, skipped, len(report.Flips))
+	}
+	if len(report.Flips) > 0 {
+		return cli.NewExitError("", 1)
+	}
+	return nil
+}
+
+func effectText(allow bool) authz.Effect {
+	if allow {
+		return authz.EffectAllow
+	}
+	return authz.EffectDeny
+}